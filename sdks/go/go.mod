@@ -2,8 +2,6 @@ module github.com/pathwell/connect-go
 
 go 1.21
 
-require (
-	github.com/golang-jwt/jwt/v5 v5.2.0
-	golang.org/x/crypto v0.17.0
-)
+require golang.org/x/crypto v0.17.0
 
+require golang.org/x/sys v0.15.0 // indirect
@@ -0,0 +1,122 @@
+package pathwell
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORS preflight headers sent by PreflightHeaders
+const (
+	OriginHeader                      = "Origin"
+	AccessControlRequestMethodHeader  = "Access-Control-Request-Method"
+	AccessControlRequestHeadersHeader = "Access-Control-Request-Headers"
+)
+
+// Capabilities is what an upstream advertises in answer to OPTIONS: the
+// Allow header and, for a CORS preflight, the Access-Control-Allow-*
+// headers. Lists are empty when the header was not sent.
+type Capabilities struct {
+	// Allow lists the methods from the Allow header, in uppercase
+	Allow []string
+
+	// AllowOrigin is Access-Control-Allow-Origin, such as "*" or an origin
+	AllowOrigin string
+	// AllowMethods lists Access-Control-Allow-Methods, in uppercase
+	AllowMethods []string
+	// AllowHeaders lists Access-Control-Allow-Headers, canonicalized
+	AllowHeaders []string
+	// ExposeHeaders lists Access-Control-Expose-Headers, canonicalized
+	ExposeHeaders []string
+	// AllowCredentials is Access-Control-Allow-Credentials: true
+	AllowCredentials bool
+	// MaxAge is Access-Control-Max-Age, or zero when absent or invalid
+	MaxAge time.Duration
+}
+
+// ParseCapabilities reads the Allow and Access-Control-Allow-* headers of
+// an OPTIONS response. Repeated and comma-separated values are merged.
+func ParseCapabilities(header http.Header) *Capabilities {
+	caps := &Capabilities{
+		Allow:            headerList(header.Values("Allow"), strings.ToUpper),
+		AllowOrigin:      strings.TrimSpace(header.Get("Access-Control-Allow-Origin")),
+		AllowMethods:     headerList(header.Values("Access-Control-Allow-Methods"), strings.ToUpper),
+		AllowHeaders:     headerList(header.Values("Access-Control-Allow-Headers"), canonicalListEntry),
+		ExposeHeaders:    headerList(header.Values("Access-Control-Expose-Headers"), canonicalListEntry),
+		AllowCredentials: strings.EqualFold(strings.TrimSpace(header.Get("Access-Control-Allow-Credentials")), "true"),
+	}
+	if seconds, err := strconv.Atoi(strings.TrimSpace(header.Get("Access-Control-Max-Age"))); err == nil && seconds > 0 {
+		caps.MaxAge = time.Duration(seconds) * time.Second
+	}
+	return caps
+}
+
+// Allows reports whether method is listed in Allow or AllowMethods. A "*"
+// in AllowMethods allows any method.
+func (caps *Capabilities) Allows(method string) bool {
+	method = strings.ToUpper(method)
+	for _, m := range caps.Allow {
+		if m == method {
+			return true
+		}
+	}
+	for _, m := range caps.AllowMethods {
+		if m == method || m == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// Discover sends a signed OPTIONS request and parses what the upstream
+// advertises. The body is discarded; a non-2xx status returns an *APIError.
+// Pass PreflightHeaders as headers to probe CORS as a browser would.
+func (c *Client) Discover(ctx context.Context, url string, headers map[string]string) (*Capabilities, error) {
+	resp, err := c.OptionsContext(ctx, url, headers)
+	if err != nil {
+		return nil, err
+	}
+	defer discardBody(resp)
+
+	if err := CheckStatus(resp); err != nil {
+		return nil, err
+	}
+	return ParseCapabilities(resp.Header), nil
+}
+
+// PreflightHeaders returns the headers of a CORS preflight for a method
+// request from origin that will send requestHeaders
+func PreflightHeaders(origin, method string, requestHeaders ...string) map[string]string {
+	headers := map[string]string{
+		OriginHeader:                     origin,
+		AccessControlRequestMethodHeader: strings.ToUpper(method),
+	}
+	if len(requestHeaders) > 0 {
+		headers[AccessControlRequestHeadersHeader] = strings.ToLower(strings.Join(requestHeaders, ", "))
+	}
+	return headers
+}
+
+// headerList splits comma-separated header values into trimmed, non-empty
+// entries passed through normalize
+func headerList(values []string, normalize func(string) string) []string {
+	var list []string
+	for _, value := range values {
+		for _, entry := range strings.Split(value, ",") {
+			if entry = strings.TrimSpace(entry); entry != "" {
+				list = append(list, normalize(entry))
+			}
+		}
+	}
+	return list
+}
+
+// canonicalListEntry canonicalizes a header name, leaving "*" alone
+func canonicalListEntry(name string) string {
+	if name == "*" {
+		return name
+	}
+	return http.CanonicalHeaderKey(name)
+}
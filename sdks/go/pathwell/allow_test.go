@@ -0,0 +1,210 @@
+package pathwell_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pathwell/connect-go/pathwell"
+	"github.com/pathwell/connect-go/pathwell/pathwelltest"
+)
+
+func TestParseCapabilities(t *testing.T) {
+	tests := []struct {
+		name   string
+		header http.Header
+		want   pathwell.Capabilities
+	}{
+		{
+			name: "empty",
+			want: pathwell.Capabilities{},
+		},
+		{
+			name:   "repeated and comma-separated Allow merged and uppercased",
+			header: http.Header{"Allow": {"get, head", " Post ,,options"}},
+			want:   pathwell.Capabilities{Allow: []string{"GET", "HEAD", "POST", "OPTIONS"}},
+		},
+		{
+			name: "CORS headers",
+			header: http.Header{
+				"Access-Control-Allow-Origin":      {" https://app.example.com "},
+				"Access-Control-Allow-Methods":     {"get,put", "delete"},
+				"Access-Control-Allow-Headers":     {"content-type, x-request-id"},
+				"Access-Control-Expose-Headers":    {"etag", "x-ratelimit-remaining"},
+				"Access-Control-Allow-Credentials": {"TRUE"},
+				"Access-Control-Max-Age":           {"600"},
+			},
+			want: pathwell.Capabilities{
+				AllowOrigin:      "https://app.example.com",
+				AllowMethods:     []string{"GET", "PUT", "DELETE"},
+				AllowHeaders:     []string{"Content-Type", "X-Request-Id"},
+				ExposeHeaders:    []string{"Etag", "X-Ratelimit-Remaining"},
+				AllowCredentials: true,
+				MaxAge:           10 * time.Minute,
+			},
+		},
+		{
+			name: "wildcards kept",
+			header: http.Header{
+				"Access-Control-Allow-Origin":   {"*"},
+				"Access-Control-Allow-Methods":  {"*"},
+				"Access-Control-Allow-Headers":  {"*"},
+				"Access-Control-Expose-Headers": {"*"},
+			},
+			want: pathwell.Capabilities{
+				AllowOrigin:   "*",
+				AllowMethods:  []string{"*"},
+				AllowHeaders:  []string{"*"},
+				ExposeHeaders: []string{"*"},
+			},
+		},
+		{
+			name:   "invalid Max-Age ignored",
+			header: http.Header{"Access-Control-Max-Age": {"ten"}},
+			want:   pathwell.Capabilities{},
+		},
+		{
+			name:   "negative Max-Age ignored",
+			header: http.Header{"Access-Control-Max-Age": {"-1"}},
+			want:   pathwell.Capabilities{},
+		},
+		{
+			name:   "credentials other than true",
+			header: http.Header{"Access-Control-Allow-Credentials": {"yes"}},
+			want:   pathwell.Capabilities{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pathwell.ParseCapabilities(tt.header); !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("ParseCapabilities = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCapabilitiesAllows(t *testing.T) {
+	tests := []struct {
+		caps   pathwell.Capabilities
+		method string
+		want   bool
+	}{
+		{pathwell.Capabilities{}, "GET", false},
+		{pathwell.Capabilities{Allow: []string{"GET", "HEAD"}}, "get", true},
+		{pathwell.Capabilities{Allow: []string{"GET", "HEAD"}}, "POST", false},
+		{pathwell.Capabilities{AllowMethods: []string{"PUT"}}, "put", true},
+		{pathwell.Capabilities{AllowMethods: []string{"*"}}, "PATCH", true},
+		// "*" only means any method in Access-Control-Allow-Methods
+		{pathwell.Capabilities{Allow: []string{"*"}}, "PATCH", false},
+	}
+	for _, tt := range tests {
+		if got := tt.caps.Allows(tt.method); got != tt.want {
+			t.Errorf("%+v.Allows(%q) = %v, want %v", tt.caps, tt.method, got, tt.want)
+		}
+	}
+}
+
+func TestPreflightHeaders(t *testing.T) {
+	tests := []struct {
+		origin, method string
+		requestHeaders []string
+		want           map[string]string
+	}{
+		{
+			origin: "https://app.example.com",
+			method: "put",
+			want: map[string]string{
+				"Origin":                        "https://app.example.com",
+				"Access-Control-Request-Method": "PUT",
+			},
+		},
+		{
+			origin:         "https://app.example.com",
+			method:         "POST",
+			requestHeaders: []string{"Content-Type", "X-Request-ID"},
+			want: map[string]string{
+				"Origin":                         "https://app.example.com",
+				"Access-Control-Request-Method":  "POST",
+				"Access-Control-Request-Headers": "content-type, x-request-id",
+			},
+		},
+	}
+	for _, tt := range tests {
+		got := pathwell.PreflightHeaders(tt.origin, tt.method, tt.requestHeaders...)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("PreflightHeaders(%q, %q, %q) = %v, want %v", tt.origin, tt.method, tt.requestHeaders, got, tt.want)
+		}
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	proxy, client := newProxyClient(t, pathwelltest.ProxyOptions{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get(pathwell.OriginHeader) == "" {
+				w.Header().Set("Allow", "GET, HEAD, OPTIONS")
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			w.Header().Set("Access-Control-Allow-Origin", r.Header.Get(pathwell.OriginHeader))
+			w.Header().Set("Access-Control-Allow-Methods", r.Header.Get(pathwell.AccessControlRequestMethodHeader))
+			w.Header().Set("Access-Control-Allow-Headers", r.Header.Get(pathwell.AccessControlRequestHeadersHeader))
+			w.Header().Set("Access-Control-Max-Age", "60")
+			w.Write([]byte("ignored"))
+		}),
+	}, pathwell.ClientOptions{})
+
+	caps, err := client.Discover(context.Background(), "/items", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(caps.Allow, []string{"GET", "HEAD", "OPTIONS"}) || !caps.Allows("head") || caps.Allows("DELETE") {
+		t.Errorf("Allow = %v", caps.Allow)
+	}
+
+	caps, err = client.Discover(context.Background(), "/items",
+		pathwell.PreflightHeaders("https://app.example.com", "delete", "X-Request-ID"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := pathwell.Capabilities{
+		AllowOrigin:  "https://app.example.com",
+		AllowMethods: []string{"DELETE"},
+		AllowHeaders: []string{"X-Request-Id"},
+		MaxAge:       time.Minute,
+	}
+	if !reflect.DeepEqual(*caps, want) {
+		t.Errorf("Discover = %+v, want %+v", *caps, want)
+	}
+
+	// Both OPTIONS requests were signed and verified by the proxy
+	requests := proxy.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("proxy verified %d requests, want 2", len(requests))
+	}
+	for _, r := range requests {
+		if r.Method != http.MethodOptions || r.Path != "/items" {
+			t.Errorf("proxy saw %s %s", r.Method, r.Path)
+		}
+	}
+	if got := requests[1].Header.Get(pathwell.AccessControlRequestMethodHeader); got != "DELETE" {
+		t.Errorf("preflight method header = %q", got)
+	}
+}
+
+func TestDiscoverStatus(t *testing.T) {
+	_, client := newProxyClient(t, pathwelltest.ProxyOptions{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Allow", "GET")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}),
+	}, pathwell.ClientOptions{})
+
+	caps, err := client.Discover(context.Background(), "/items", nil)
+	var apiErr *pathwell.APIError
+	if caps != nil || !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("Discover = %v, %v; want an *APIError for 405", caps, err)
+	}
+}
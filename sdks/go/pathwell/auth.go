@@ -1,18 +1,60 @@
 package pathwell
 
 import (
-	"crypto/hmac"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha256"
 	"crypto/x509"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/pem"
 	"fmt"
+	"io"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// maxPrivateKeySize bounds how much LoadPrivateKeyFromReader reads; a 4096-bit
+// RSA key in PEM is about 3.3 KiB
+const maxPrivateKeySize = 64 << 10
+
+// KeyAlgorithm selects the type of key produced by GenerateKeyPairWithAlgorithm
+type KeyAlgorithm int
+
+const (
+	// RSA2048 generates a 2048-bit RSA key
+	RSA2048 KeyAlgorithm = iota
+	// RSA4096 generates a 4096-bit RSA key
+	RSA4096
+	// Ed25519 generates an Ed25519 key, which signs much faster than RSA
+	Ed25519
+	// ECDSAP256 generates an ECDSA key on the NIST P-256 curve, for
+	// environments that mandate it
+	ECDSAP256
+)
+
+// String returns the name of the algorithm
+func (a KeyAlgorithm) String() string {
+	switch a {
+	case RSA2048:
+		return "RSA2048"
+	case RSA4096:
+		return "RSA4096"
+	case Ed25519:
+		return "Ed25519"
+	case ECDSAP256:
+		return "ECDSAP256"
+	default:
+		return fmt.Sprintf("KeyAlgorithm(%d)", int(a))
+	}
+}
+
 // KeyPair represents a public/private key pair
 type KeyPair struct {
 	PrivateKey string
@@ -21,82 +63,485 @@ type KeyPair struct {
 
 // GenerateKeyPair generates a new RSA key pair for agent authentication
 func GenerateKeyPair() (*KeyPair, error) {
-	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	return GenerateKeyPairWithAlgorithm(RSA2048)
+}
+
+// GenerateKeyPairWithAlgorithm generates a new key pair of the given algorithm.
+// RSA private keys are encoded as PKCS#1, Ed25519 and ECDSA private keys as
+// PKCS#8.
+func GenerateKeyPairWithAlgorithm(alg KeyAlgorithm) (*KeyPair, error) {
+	var privateKeyBlock *pem.Block
+	var publicKey crypto.PublicKey
+
+	switch alg {
+	case RSA2048, RSA4096:
+		bits := 2048
+		if alg == RSA4096 {
+			bits = 4096
+		}
+		privateKey, err := rsa.GenerateKey(rand.Reader, bits)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate key pair: %w", err)
+		}
+		privateKeyBlock = &pem.Block{
+			Type:  "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
+		}
+		publicKey = &privateKey.PublicKey
+	case Ed25519:
+		pub, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate key pair: %w", err)
+		}
+		privateKeyDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal private key: %w", err)
+		}
+		privateKeyBlock = &pem.Block{
+			Type:  "PRIVATE KEY",
+			Bytes: privateKeyDER,
+		}
+		publicKey = pub
+	case ECDSAP256:
+		privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate key pair: %w", err)
+		}
+		privateKeyDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal private key: %w", err)
+		}
+		privateKeyBlock = &pem.Block{
+			Type:  "PRIVATE KEY",
+			Bytes: privateKeyDER,
+		}
+		publicKey = &privateKey.PublicKey
+	default:
+		return nil, fmt.Errorf("unsupported key algorithm: %s", alg)
+	}
+
+	publicKeyPEM, err := encodePublicKey(publicKey)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate key pair: %w", err)
+		return nil, err
 	}
 
-	privateKeyPEM := pem.EncodeToMemory(&pem.Block{
-		Type:  "RSA PRIVATE KEY",
-		Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
-	})
+	return &KeyPair{
+		PrivateKey: string(pem.EncodeToMemory(privateKeyBlock)),
+		PublicKey:  publicKeyPEM,
+	}, nil
+}
 
-	publicKeyDER, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+// encodePublicKey encodes a public key as PEM (PKIX), the form of
+// KeyPair.PublicKey
+func encodePublicKey(publicKey crypto.PublicKey) (string, error) {
+	publicKeyDER, err := x509.MarshalPKIXPublicKey(publicKey)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal public key: %w", err)
+		return "", fmt.Errorf("failed to marshal public key: %w", err)
 	}
 
-	publicKeyPEM := pem.EncodeToMemory(&pem.Block{
+	return string(pem.EncodeToMemory(&pem.Block{
 		Type:  "PUBLIC KEY",
 		Bytes: publicKeyDER,
-	})
-
-	return &KeyPair{
-		PrivateKey: string(privateKeyPEM),
-		PublicKey:  string(publicKeyPEM),
-	}, nil
+	})), nil
 }
 
 // LoadPrivateKey loads a private key from a file path
 func LoadPrivateKey(keyPath string) (string, error) {
-	data, err := os.ReadFile(keyPath)
+	f, err := os.Open(keyPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read private key file: %w", err)
+	}
+	defer f.Close()
+
+	key, err := LoadPrivateKeyFromReader(f)
 	if err != nil {
 		return "", fmt.Errorf("failed to read private key file: %w", err)
 	}
+	return key, nil
+}
+
+// LoadPrivateKeyFromReader reads a PEM private key from r, such as stdin or a
+// decryption stream. Reads are capped at maxPrivateKeySize.
+func LoadPrivateKeyFromReader(r io.Reader) (string, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxPrivateKeySize+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to read private key: %w", err)
+	}
+	if len(data) > maxPrivateKeySize {
+		return "", fmt.Errorf("private key is larger than %d bytes", maxPrivateKeySize)
+	}
 	return string(data), nil
 }
 
-// SignRequest signs a request using the agent's private key
+// ParsePrivateKey decodes a PEM private key into a signer. The key is parsed
+// as PKCS#1 first and falls back to PKCS#8 ("BEGIN PRIVATE KEY"), the default
+// of openssl genpkey and most KMS exports, then to SEC 1 ("BEGIN EC PRIVATE
+// KEY"), as written by openssl ecparam. EC keys must be on P-256.
+func ParsePrivateKey(privateKeyPEM string) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(privateKeyPEM))
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode PEM block")
+	}
+	if isEncryptedBlock(block) {
+		return nil, fmt.Errorf("private key is encrypted; use ParsePrivateKeyWithPassphrase")
+	}
+
+	return parsePrivateKeyDER(block.Bytes)
+}
+
+// parsePrivateKeyDER parses a DER private key as PKCS#1, then PKCS#8, then
+// SEC 1
+func parsePrivateKeyDER(der []byte) (crypto.Signer, error) {
+	if privateKey, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return privateKey, nil
+	}
+
+	parsedKey, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		ecKey, ecErr := x509.ParseECPrivateKey(der)
+		if ecErr != nil {
+			return nil, fmt.Errorf("failed to parse private key as PKCS#1, PKCS#8 or SEC 1: %w", err)
+		}
+		parsedKey = ecKey
+	}
+
+	switch key := parsedKey.(type) {
+	case *rsa.PrivateKey:
+		return key, nil
+	case ed25519.PrivateKey:
+		return key, nil
+	case *ecdsa.PrivateKey:
+		if err := checkPublicKeyType(&key.PublicKey); err != nil {
+			return nil, err
+		}
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", parsedKey)
+	}
+}
+
+// checkPublicKeyType rejects key types the signing scheme does not support
+func checkPublicKeyType(publicKey crypto.PublicKey) error {
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey, ed25519.PublicKey:
+		return nil
+	case *ecdsa.PublicKey:
+		if key.Curve != elliptic.P256() {
+			return fmt.Errorf("unsupported ECDSA curve %s: only P-256 is supported", key.Curve.Params().Name)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+}
+
+// ParsePublicKey decodes a PEM (PKIX) public key, such as KeyPair.PublicKey
+func ParsePublicKey(publicKeyPEM string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(publicKeyPEM))
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode PEM block")
+	}
+
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+	return publicKey, nil
+}
+
+// keyPairProbe is the payload KeyPairMatches signs and verifies
+const keyPairProbe = "pathwell key pair check"
+
+// KeyPairMatches reports whether publicPEM is the public half of
+// privatePEM, by signing a probe payload with the private key and verifying
+// it with the public one. Run it at startup against the registered public
+// key to catch a mismatch before the proxy answers every request with 401.
+// An error means a key could not be parsed or is of an unsupported type; a
+// pair of different key types is reported as a mismatch.
+func KeyPairMatches(privatePEM, publicPEM string) (bool, error) {
+	signer, err := ParsePrivateKey(privatePEM)
+	if err != nil {
+		return false, err
+	}
+	publicKey, err := ParsePublicKey(publicPEM)
+	if err != nil {
+		return false, err
+	}
+	if err := checkPublicKeyType(publicKey); err != nil {
+		return false, err
+	}
+
+	signature, err := signPayload(signer, []byte(keyPairProbe))
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", ErrSigning, err)
+	}
+	return verifyPayload(publicKey, []byte(keyPairProbe), signature) == nil, nil
+}
+
+// GenerateNonce returns a random 128-bit hex nonce for replay protection
+func GenerateNonce() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// SignatureInput holds everything covered by a request signature. The
+// canonical payload is
+//
+//	METHOD\nPATH\nTIMESTAMP\nNONCE\nBODY_HASH
+//
+// followed by one "name:value" line for each optional field that is set,
+// in this order: key-id, idempotency-key, host. Signed headers follow as one
+// lowercase "name:value" line each, sorted by name.
+type SignatureInput struct {
+	Method    string
+	Path      string
+	Body      []byte
+	Timestamp string
+	Nonce     string
+	// KeyID identifies the key used to sign, so the proxy can pick the
+	// right public key during rotation
+	KeyID string
+	// IdempotencyKey is the Idempotency-Key header value, signed so it
+	// cannot be swapped to defeat duplicate detection
+	IdempotencyKey string
+	// Host is the canonical scheme://host[:port] of the upstream, as
+	// returned by CanonicalHost, when the client binds the signature to it
+	Host string
+	// BodyHash, when set, is used as the lowercase hex SHA-256 of the body
+	// instead of hashing Body, for bodies that are streamed. The digest of
+	// an empty body is signed as "", like an empty Body.
+	BodyHash string
+	// Headers are request headers covered by the signature, by name. Their
+	// names are sent in X-Pathwell-Signed-Headers.
+	Headers map[string]string
+}
+
+// Payload returns the canonical string covered by the signature, including
+// the optional lines. It is exactly what the client signs, so both sides
+// can log it to diff a rejected signature. Unlike Sign, it leaves an empty
+// Timestamp empty.
+func (in SignatureInput) Payload() string {
+	bodyHash := normalizeBodyHash(in.BodyHash)
+	if bodyHash == "" {
+		bodyHash = hashBody(in.Body)
+	}
+	payload := canonicalPayload(in.Method, in.Path, bodyHash, in.Timestamp, in.Nonce)
+	if in.KeyID != "" {
+		payload += "\nkey-id:" + in.KeyID
+	}
+	if in.IdempotencyKey != "" {
+		payload += "\nidempotency-key:" + in.IdempotencyKey
+	}
+	if in.Host != "" {
+		payload += "\nhost:" + in.Host
+	}
+	payload += signedHeaderLines(in.Headers)
+	return payload
+}
+
+// CanonicalPayload returns the base lines of the signed payload, which is
+// the exact string SignRequest signs:
+//
+//	METHOD\nPATH\nTIMESTAMP\nNONCE\nBODY_HASH
+//
+// METHOD is uppercased, PATH is canonicalized with CanonicalPath and
+// BODY_HASH is the lowercase hex SHA-256 of body, or empty when body is
+// empty; nil and zero-length bodies sign identically. A client using
+// KeyID, IdempotencyKeys, SignedHeaders or IncludeHostInSignature signs
+// further lines after these; SignatureInput.Payload returns the full string.
+func CanonicalPayload(method, path string, body []byte, timestamp, nonce string) string {
+	return canonicalPayload(method, path, hashBody(body), timestamp, nonce)
+}
+
+// canonicalPayload builds the payload from an already computed body hash
+func canonicalPayload(method, path, bodyHash, timestamp, nonce string) string {
+	return fmt.Sprintf("%s\n%s\n%s\n%s\n%s",
+		strings.ToUpper(method), CanonicalPath(path), timestamp, nonce, bodyHash)
+}
+
+// emptyBodyHash is the hex SHA-256 of zero bytes. It never appears in a
+// payload: an empty body, whether nil, "" or []byte{}, signs as an empty
+// BODY_HASH, and a BodyHash or streamed hash equal to it is treated the same.
+const emptyBodyHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// normalizeBodyHash maps the digest of an empty body to "", the form the
+// payload uses for empty bodies
+func normalizeBodyHash(bodyHash string) string {
+	if strings.EqualFold(bodyHash, emptyBodyHash) {
+		return ""
+	}
+	return bodyHash
+}
+
+// hashBody returns the hex SHA-256 of body, or "" when body is empty
+func hashBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	hash := sha256.Sum256(body)
+	return fmt.Sprintf("%x", hash)
+}
+
+// Sign signs the input with signer and returns the base64 signature.
+// An empty Timestamp defaults to the current Unix time.
+func (in SignatureInput) Sign(signer crypto.Signer) (string, error) {
+	if in.Timestamp == "" {
+		in.Timestamp = fmt.Sprintf("%d", time.Now().Unix())
+	}
+
+	signature, err := signPayload(signer, []byte(in.Payload()))
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrSigning, err)
+	}
+
+	return base64.StdEncoding.EncodeToString(signature), nil
+}
+
+// Verify checks a base64 signature over the input against a PEM public key
+func (in SignatureInput) Verify(publicKeyPEM string, signature string) error {
+	publicKey, err := ParsePublicKey(publicKeyPEM)
+	if err != nil {
+		return err
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("failed to decode signature: %w", err)
+	}
+
+	if err := verifyPayload(publicKey, []byte(in.Payload()), sig); err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+
+	return nil
+}
+
+// signPayload signs payload with the given key. RSA keys sign the SHA-256
+// digest with PKCS#1 v1.5 and ECDSA keys sign it as an ASN.1 DER (r, s)
+// sequence; Ed25519 keys sign the payload directly.
+func signPayload(signer crypto.Signer, payload []byte) ([]byte, error) {
+	if _, ok := signer.Public().(ed25519.PublicKey); ok {
+		return signer.Sign(rand.Reader, payload, crypto.Hash(0))
+	}
+
+	digest := sha256.Sum256(payload)
+	return signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+}
+
+// verifyPayload checks a signature produced by signPayload
+func verifyPayload(publicKey crypto.PublicKey, payload, signature []byte) error {
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		digest := sha256.Sum256(payload)
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature)
+	case ed25519.PublicKey:
+		if !ed25519.Verify(key, payload, signature) {
+			return fmt.Errorf("ed25519: verification error")
+		}
+		return nil
+	case *ecdsa.PublicKey:
+		if err := checkPublicKeyType(key); err != nil {
+			return err
+		}
+		digest := sha256.Sum256(payload)
+		if !ecdsa.VerifyASN1(key, digest[:], signature) {
+			return fmt.Errorf("ecdsa: verification error")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+}
+
+// SignRequest signs a request using the agent's private key.
+// The payload "method\npath\ntimestamp\nnonce\nbodyHash" is signed with
+// RSASSA-PKCS1-v1_5 over its SHA-256 digest for RSA keys, with ECDSA over the
+// same digest for P-256 keys (ASN.1 DER encoded), or with Ed25519 directly
+// for Ed25519 keys. The signature is returned base64 encoded.
 func SignRequest(
 	privateKeyPEM string,
 	method string,
 	path string,
 	body []byte,
 	timestamp string,
+	nonce string,
 ) (string, error) {
-	if timestamp == "" {
-		timestamp = fmt.Sprintf("%d", time.Now().Unix())
+	privateKey, err := ParsePrivateKey(privateKeyPEM)
+	if err != nil {
+		return "", err
 	}
 
-	// Create signature payload
-	var bodyHash string
-	if len(body) > 0 {
-		hash := sha256.Sum256(body)
-		bodyHash = fmt.Sprintf("%x", hash)
-	}
+	return SignRequestWithSigner(privateKey, method, path, body, timestamp, nonce)
+}
 
-	payload := fmt.Sprintf("%s\n%s\n%s\n%s", method, path, timestamp, bodyHash)
+// SignRequestWithSigner signs a request like SignRequest, with a key already
+// parsed by ParsePrivateKey. Parse the key once and reuse it to keep PEM
+// decoding off the hot path of high-volume agents.
+func SignRequestWithSigner(
+	signer crypto.Signer,
+	method string,
+	path string,
+	body []byte,
+	timestamp string,
+	nonce string,
+) (string, error) {
+	return SignatureInput{
+		Method:    method,
+		Path:      path,
+		Body:      body,
+		Timestamp: timestamp,
+		Nonce:     nonce,
+	}.Sign(signer)
+}
 
-	// Parse private key
-	block, _ := pem.Decode([]byte(privateKeyPEM))
-	if block == nil {
-		return "", fmt.Errorf("failed to decode PEM block")
-	}
+// VerifySignature verifies a request signature produced by SignRequest
+// using the agent's public key
+func VerifySignature(
+	publicKeyPEM string,
+	method string,
+	path string,
+	body []byte,
+	timestamp string,
+	nonce string,
+	signature string,
+) error {
+	return SignatureInput{
+		Method:    method,
+		Path:      path,
+		Body:      body,
+		Timestamp: timestamp,
+		Nonce:     nonce,
+	}.Verify(publicKeyPEM, signature)
+}
 
-	privateKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+// VerifySignatureWithSkew verifies a request signature like VerifySignature
+// and additionally rejects timestamps more than maxSkew away from now
+func VerifySignatureWithSkew(
+	publicKeyPEM string,
+	method string,
+	path string,
+	body []byte,
+	timestamp string,
+	nonce string,
+	signature string,
+	maxSkew time.Duration,
+	now time.Time,
+) error {
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse private key: %w", err)
+		return fmt.Errorf("invalid timestamp %q: %w", timestamp, err)
 	}
 
-	// For MVP, use HMAC with private key material
-	// In production, this would use proper cryptographic signing
-	keyBytes := x509.MarshalPKCS1PrivateKey(privateKey)
-	hmacKey := keyBytes[:32]
-
-	mac := hmac.New(sha256.New, hmacKey)
-	mac.Write([]byte(payload))
-	signature := mac.Sum(nil)
+	skew := now.Sub(time.Unix(unix, 0))
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > maxSkew {
+		return fmt.Errorf("timestamp %s is outside the allowed skew of %s", timestamp, maxSkew)
+	}
 
-	return base64.StdEncoding.EncodeToString(signature), nil
+	return VerifySignature(publicKeyPEM, method, path, body, timestamp, nonce, signature)
 }
-
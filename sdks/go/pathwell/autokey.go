@@ -0,0 +1,109 @@
+package pathwell
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// publicKeySuffix is appended to PrivateKeyPath to name the public key
+// written by NewClientAutoKey
+const publicKeySuffix = ".pub"
+
+// NewClientAutoKey creates a client like NewClient, first provisioning the
+// key at options.PrivateKeyPath, or PATHWELL_PRIVATE_KEY_PATH, if it does
+// not exist yet: a key pair is generated as by GenerateKeyPair and written
+// there, with the public key next to it at PrivateKeyPath + ".pub". The
+// public key PEM is returned when this call created the key, so it can be
+// registered with the proxy, and is "" when an existing key was loaded. A
+// missing ".pub" next to an existing key, left by a crash during
+// provisioning, is written again from the private key.
+//
+// Processes starting at the same time are safe: the private key is linked
+// into place atomically, exactly one of them creates it and the others load
+// the key it wrote.
+func NewClientAutoKey(options ClientOptions) (*Client, string, error) {
+	options = applyEnvDefaults(options)
+	if options.PrivateKeyPath == "" {
+		return nil, "", fmt.Errorf("PrivateKeyPath or %s is required to provision a key", PrivateKeyPathEnv)
+	}
+	if options.PrivateKeyPEM != "" || options.Signer != nil {
+		return nil, "", fmt.Errorf("PrivateKeyPEM and Signer cannot be used with NewClientAutoKey")
+	}
+
+	publicKeyPEM, err := provisionKey(options.PrivateKeyPath, options.Passphrase)
+	if err != nil {
+		return nil, "", err
+	}
+
+	client, err := NewClient(options)
+	if err != nil {
+		return nil, "", err
+	}
+	return client, publicKeyPEM, nil
+}
+
+// provisionKey writes a new key pair unless privatePath exists, returning
+// the public key PEM if it wrote one
+func provisionKey(privatePath, passphrase string) (string, error) {
+	if _, err := os.Stat(privatePath); err == nil {
+		return "", restorePublicKey(privatePath, passphrase)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("failed to check key file %s: %w", privatePath, err)
+	}
+	if passphrase != "" {
+		return "", fmt.Errorf("key file %s does not exist and encrypted keys cannot be generated", privatePath)
+	}
+
+	kp, err := GenerateKeyPair()
+	if err != nil {
+		return "", err
+	}
+	err = writeFileAtomic(privatePath, []byte(kp.PrivateKey), 0o600, false)
+	if errors.Is(err, fs.ErrExist) {
+		// Another process provisioned the key first; use theirs
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to write private key: %w", err)
+	}
+
+	// The public key is derived from the private key we just won with, so
+	// replacing a stale one is correct
+	if err := writeFileAtomic(privatePath+publicKeySuffix, []byte(kp.PublicKey), 0o644, true); err != nil {
+		return "", fmt.Errorf("failed to write public key: %w", err)
+	}
+	return kp.PublicKey, nil
+}
+
+// restorePublicKey writes the public key of the existing key at privatePath
+// if its ".pub" is missing. A key that does not parse is left for NewClient
+// to report.
+func restorePublicKey(privatePath, passphrase string) error {
+	publicPath := privatePath + publicKeySuffix
+	if _, err := os.Stat(publicPath); err == nil {
+		return nil
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to check key file %s: %w", publicPath, err)
+	}
+
+	privateKeyPEM, err := LoadPrivateKey(privatePath)
+	if err != nil {
+		return nil
+	}
+	signer, err := ParsePrivateKeyWithPassphrase(privateKeyPEM, passphrase)
+	if err != nil {
+		return nil
+	}
+	publicKeyPEM, err := encodePublicKey(signer.Public())
+	if err != nil {
+		return err
+	}
+	// Another process restoring it at the same time writes the same key
+	err = writeFileAtomic(publicPath, []byte(publicKeyPEM), 0o644, false)
+	if err != nil && !errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("failed to write public key: %w", err)
+	}
+	return nil
+}
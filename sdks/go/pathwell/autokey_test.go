@@ -0,0 +1,110 @@
+package pathwell_test
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/pathwell/connect-go/pathwell"
+)
+
+func TestNewClientAutoKeyConcurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agent.key")
+
+	const n = 8
+	var (
+		wg         sync.WaitGroup
+		clients    [n]*pathwell.Client
+		publicKeys [n]string
+		errs       [n]error
+	)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			clients[i], publicKeys[i], errs[i] = pathwell.NewClientAutoKey(pathwell.ClientOptions{
+				AgentID:        "agent-123",
+				PrivateKeyPath: path,
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	var created string
+	for i := 0; i < n; i++ {
+		if errs[i] != nil {
+			t.Fatalf("client %d: %v", i, errs[i])
+		}
+		if publicKeys[i] == "" {
+			continue
+		}
+		if created != "" {
+			t.Fatalf("more than one client created the key")
+		}
+		created = publicKeys[i]
+	}
+	if created == "" {
+		t.Fatal("no client reported creating the key")
+	}
+
+	for i, client := range clients {
+		publicKey, err := client.PublicKeyPEM()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if publicKey != created {
+			t.Errorf("client %d signs with a different key", i)
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0o600 {
+		t.Errorf("private key mode = %o, want 600", mode)
+	}
+	written, err := os.ReadFile(path + ".pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(written) != created {
+		t.Error("public key file does not match the created key")
+	}
+}
+
+func TestNewClientAutoKeyRestoresPublicKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agent.key")
+	_, created, err := pathwell.NewClientAutoKey(pathwell.ClientOptions{AgentID: "agent-123", PrivateKeyPath: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A crash between linking the private key and writing the public key
+	if err := os.Remove(path + ".pub"); err != nil {
+		t.Fatal(err)
+	}
+
+	_, publicKey, err := pathwell.NewClientAutoKey(pathwell.ClientOptions{AgentID: "agent-123", PrivateKeyPath: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if publicKey != "" {
+		t.Error("loading an existing key reported creating one")
+	}
+	written, err := os.ReadFile(path + ".pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(written) != created {
+		t.Error("restored public key does not match the private key")
+	}
+	info, err := os.Stat(path + ".pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0o644 {
+		t.Errorf("public key mode = %o, want 644", mode)
+	}
+}
@@ -0,0 +1,59 @@
+package pathwell
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// defaultBatchConcurrency is how many batch requests run at once by default
+const defaultBatchConcurrency = 8
+
+// Request is one call in a CallBatch
+type Request struct {
+	Method  string
+	URL     string
+	Headers map[string]string
+	Body    interface{}
+}
+
+// Result is the outcome of one Request in a CallBatch. Exactly one of
+// Response and Err is set; the caller must close Response.Body.
+type Result struct {
+	Response *http.Response
+	Err      error
+}
+
+// CallBatch sends requests concurrently, at most BatchConcurrency at a time,
+// each signed independently with its own timestamp and nonce. Results are in
+// the same order as requests. A failed request records its error in its
+// Result and does not stop the others; the returned error is only set when
+// ctx ends before every request was started.
+func (c *Client) CallBatch(ctx context.Context, requests []Request) ([]Result, error) {
+	results := make([]Result, len(requests))
+	sem := make(chan struct{}, c.batchConcurrency)
+	var wg sync.WaitGroup
+
+	for i, req := range requests {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			for j := i; j < len(requests); j++ {
+				results[j].Err = ctx.Err()
+			}
+			wg.Wait()
+			return results, ctx.Err()
+		}
+
+		wg.Add(1)
+		go func(i int, req Request) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			resp, err := c.CallContext(ctx, req.Method, req.URL, req.Headers, req.Body)
+			results[i] = Result{Response: resp, Err: err}
+		}(i, req)
+	}
+
+	wg.Wait()
+	return results, nil
+}
@@ -0,0 +1,171 @@
+package pathwell
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// MultipartFile is a file part of a MultipartBody
+type MultipartFile struct {
+	FieldName   string
+	FileName    string
+	ContentType string
+	Content     io.Reader
+}
+
+// MultipartBody is a request body sent as multipart/form-data. The parts are
+// buffered in memory so the signature can cover the exact bytes sent.
+type MultipartBody struct {
+	Fields map[string]string
+	Files  []MultipartFile
+}
+
+// quoteEscaper escapes quoted Content-Disposition parameters, as mime/multipart does
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// MarshalFunc encodes a JSON request body. The bytes it returns are exactly
+// what is hashed, signed and sent.
+type MarshalFunc func(v interface{}) ([]byte, error)
+
+// marshalWithoutHTMLEscaping encodes v like json.Marshal, but leaves <, > and
+// & as they are instead of escaping them to \u003c, \u003e and \u0026
+func marshalWithoutHTMLEscaping(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	// Encode terminates each value with a newline that Marshal does not add
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// encodeBody converts a Call body into the bytes to sign and send, plus the
+// Content-Type implied by the body type, if any. Values without a dedicated
+// encoding are marshaled as JSON with marshal.
+func encodeBody(body interface{}, marshal MarshalFunc) ([]byte, string, error) {
+	switch b := body.(type) {
+	case nil:
+		return nil, "", nil
+	case string:
+		return []byte(b), "", nil
+	case []byte:
+		return b, "", nil
+	case url.Values:
+		return []byte(b.Encode()), "application/x-www-form-urlencoded", nil
+	case *MultipartBody:
+		return b.encode()
+	case MultipartBody:
+		return b.encode()
+	case io.Reader:
+		// The whole body is needed to hash it; CallWithHashedBody streams
+		// instead when the hash is known up front
+		bodyBytes, err := io.ReadAll(b)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read body: %w", err)
+		}
+		return bodyBytes, "", nil
+	default:
+		bodyBytes, err := marshal(body)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to marshal body: %w", err)
+		}
+		return bodyBytes, "", nil
+	}
+}
+
+// encode writes the multipart form and returns it with its boundary Content-Type
+func (m *MultipartBody) encode() ([]byte, string, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	// Fields are written in name order so equal forms encode identically,
+	// apart from the random boundary
+	names := make([]string, 0, len(m.Fields))
+	for name := range m.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := writer.WriteField(name, m.Fields[name]); err != nil {
+			return nil, "", fmt.Errorf("failed to write multipart field %q: %w", name, err)
+		}
+	}
+
+	for _, file := range m.Files {
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+			quoteEscaper.Replace(file.FieldName), quoteEscaper.Replace(file.FileName)))
+		contentType := file.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		header.Set("Content-Type", contentType)
+
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to create multipart file %q: %w", file.FileName, err)
+		}
+		if _, err := io.Copy(part, file.Content); err != nil {
+			return nil, "", fmt.Errorf("failed to write multipart file %q: %w", file.FileName, err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, "", fmt.Errorf("failed to close multipart body: %w", err)
+	}
+
+	return buf.Bytes(), writer.FormDataContentType(), nil
+}
+
+// gzipBody compresses a request body
+func gzipBody(body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(body); err != nil {
+		return nil, fmt.Errorf("failed to compress body: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress body: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// hasHeader reports whether headers sets name, compared case-insensitively
+func hasHeader(headers map[string]string, name string) bool {
+	name = http.CanonicalHeaderKey(name)
+	for k := range headers {
+		if http.CanonicalHeaderKey(k) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// withHeader returns a copy of headers with name set to value
+func withHeader(headers map[string]string, name, value string) map[string]string {
+	merged := make(map[string]string, len(headers)+1)
+	for k, v := range headers {
+		merged[k] = v
+	}
+	merged[name] = value
+	return merged
+}
+
+// withDefaultHeader returns headers with name set to value unless headers
+// already sets it
+func withDefaultHeader(headers map[string]string, name, value string) map[string]string {
+	if hasHeader(headers, name) {
+		return headers
+	}
+	return withHeader(headers, name, value)
+}
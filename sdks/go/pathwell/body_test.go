@@ -0,0 +1,138 @@
+package pathwell_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/pathwell/connect-go/pathwell"
+	"github.com/pathwell/connect-go/pathwell/pathwelltest"
+)
+
+func TestFormBody(t *testing.T) {
+	proxy, client := newProxyClient(t, pathwelltest.ProxyOptions{}, pathwell.ClientOptions{TargetURL: "https://api.example.com"})
+
+	form := url.Values{
+		"b":    {"x y&z"},
+		"a":    {"1", "2"},
+		"note": {"café=ok"},
+	}
+	resp, err := client.Post("/form", nil, form)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status %d", resp.StatusCode)
+	}
+
+	got := proxy.Requests()[0]
+	if want := "a=1&a=2&b=x+y%26z&note=caf%C3%A9%3Dok"; string(got.Body) != want {
+		t.Errorf("body = %q, want %q", got.Body, want)
+	}
+	if ct := got.Header.Get("Content-Type"); ct != "application/x-www-form-urlencoded" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+}
+
+func TestFormBodyKeepsCallerContentType(t *testing.T) {
+	proxy, client := newProxyClient(t, pathwelltest.ProxyOptions{}, pathwell.ClientOptions{TargetURL: "https://api.example.com"})
+
+	headers := map[string]string{"content-type": "application/x-www-form-urlencoded; charset=utf-8"}
+	resp, err := client.Post("/form", headers, url.Values{"a": {"1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if ct := proxy.Requests()[0].Header.Values("Content-Type"); len(ct) != 1 || ct[0] != headers["content-type"] {
+		t.Errorf("Content-Type = %q, want the caller's only", ct)
+	}
+}
+
+// JSON bodies with <, > and & verify at the proxy whichever encoder is used,
+// because the bytes hashed are the bytes sent
+func TestJSONHTMLEscaping(t *testing.T) {
+	body := map[string]string{"html": `<a href="/x?a=1&b=2">link</a>`}
+	tests := []struct {
+		name    string
+		options pathwell.ClientOptions
+		want    string
+	}{
+		{"default", pathwell.ClientOptions{}, `{"html":"\u003ca href=\"/x?a=1\u0026b=2\"\u003elink\u003c/a\u003e"}`},
+		{"DisableHTMLEscaping", pathwell.ClientOptions{DisableHTMLEscaping: true}, `{"html":"<a href=\"/x?a=1&b=2\">link</a>"}`},
+		{"JSONMarshal", pathwell.ClientOptions{
+			DisableHTMLEscaping: true,
+			JSONMarshal: func(v interface{}) ([]byte, error) {
+				return []byte(`{ "custom" : "<&>" }`), nil
+			},
+		}, `{ "custom" : "<&>" }`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := tt.options
+			options.TargetURL = "https://api.example.com"
+			proxy, client := newProxyClient(t, pathwelltest.ProxyOptions{}, options)
+
+			resp, err := client.Post("/v1/items", nil, body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("status %d", resp.StatusCode)
+			}
+			if got := string(proxy.Requests()[0].Body); got != tt.want {
+				t.Errorf("body = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONMarshalError(t *testing.T) {
+	_, client := newProxyClient(t, pathwelltest.ProxyOptions{}, pathwell.ClientOptions{
+		TargetURL: "https://api.example.com",
+		JSONMarshal: func(v interface{}) ([]byte, error) {
+			return nil, errors.New("boom")
+		},
+	})
+	if _, err := client.Post("/v1/items", nil, map[string]int{"n": 1}); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("error = %v, want the marshal error", err)
+	}
+}
+
+// nil, "" and []byte{} bodies, and a streamed body declared with the empty
+// digest, all verify at the proxy and arrive empty
+func TestEmptyBodies(t *testing.T) {
+	proxy, client := newProxyClient(t, pathwelltest.ProxyOptions{}, pathwell.ClientOptions{TargetURL: "https://api.example.com"})
+
+	for _, body := range []interface{}{nil, "", []byte{}, strings.NewReader("")} {
+		resp, err := client.Post("/x", nil, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("body %#v: status %d", body, resp.StatusCode)
+		}
+	}
+	const emptyDigest = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	for _, hash := range []string{"", emptyDigest} {
+		resp, err := client.CallWithHashedBody(context.Background(), "POST", "/x", nil, strings.NewReader(""), 0, hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("streamed with hash %q: status %d", hash, resp.StatusCode)
+		}
+	}
+
+	for i, r := range proxy.Requests() {
+		if len(r.Body) != 0 {
+			t.Errorf("request %d body = %q", i, r.Body)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package pathwell
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// defaultCircuitBreakerCooldown is how long an open circuit rejects calls
+const defaultCircuitBreakerCooldown = 30 * time.Second
+
+// CircuitOpenError is returned without contacting the proxy while the
+// circuit breaker is open
+type CircuitOpenError struct {
+	// Until is when the breaker lets a trial request through
+	Until time.Time
+}
+
+// Error implements the error interface
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("pathwell: circuit breaker open until %s", e.Until.Format(time.RFC3339))
+}
+
+// circuitBreaker opens after threshold consecutive failures, rejects
+// requests for the cooldown, then lets a single trial request through.
+// The trial closes the circuit on success and reopens it on failure.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	trial     bool
+}
+
+// newCircuitBreaker returns nil, a disabled breaker, when threshold is zero
+func newCircuitBreaker(options ClientOptions) *circuitBreaker {
+	if options.CircuitBreakerThreshold <= 0 {
+		return nil
+	}
+	cooldown := options.CircuitBreakerCooldown
+	if cooldown <= 0 {
+		cooldown = defaultCircuitBreakerCooldown
+	}
+	return &circuitBreaker{
+		threshold: options.CircuitBreakerThreshold,
+		cooldown:  cooldown,
+	}
+}
+
+// allow reports whether a request may be sent, returning a
+// *CircuitOpenError if not
+func (b *circuitBreaker) allow() error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return nil
+	}
+	if b.trial || time.Now().Before(b.openUntil) {
+		return &CircuitOpenError{Until: b.openUntil}
+	}
+	b.trial = true
+	return nil
+}
+
+// record counts the outcome of a request let through by allow. Transport
+// errors and 5xx responses are failures; errors raised before sending, such
+// as signing failures, say nothing about the proxy and are not counted.
+func (b *circuitBreaker) record(resp *http.Response, err error) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+	if err != nil && !errors.Is(err, ErrTransport) {
+		return
+	}
+	if err == nil && resp.StatusCode < http.StatusInternalServerError {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openUntil = time.Now().Add(b.cooldown)
+	}
+}
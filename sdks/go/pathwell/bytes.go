@@ -0,0 +1,42 @@
+package pathwell
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// GetBytes makes a GET request and returns the whole response body and the
+// status code, closing the body. The body is bounded by MaxResponseBytes
+// and decompressed if gzip or deflate encoded. A non-2xx status returns an
+// *APIError along with the status code.
+func (c *Client) GetBytes(url string, headers map[string]string) ([]byte, int, error) {
+	return c.GetBytesContext(context.Background(), url, headers)
+}
+
+// GetBytesContext is GetBytes bound to ctx
+func (c *Client) GetBytesContext(ctx context.Context, url string, headers map[string]string) ([]byte, int, error) {
+	resp, err := c.GetContext(ctx, url, headers)
+	if err != nil {
+		// With ErrorOnHTTPError the status only survives in the APIError
+		var apiErr *APIError
+		if errors.As(err, &apiErr) {
+			return nil, apiErr.StatusCode, err
+		}
+		return nil, 0, err
+	}
+	defer discardBody(resp)
+
+	if err := decompressResponse(resp); err != nil {
+		return nil, resp.StatusCode, err
+	}
+	if err := CheckStatus(resp); err != nil {
+		return nil, resp.StatusCode, err
+	}
+
+	data, err := readLimited(resp.Body, c.maxResponseBytes)
+	if err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("failed to read response body: %w", err)
+	}
+	return data, resp.StatusCode, nil
+}
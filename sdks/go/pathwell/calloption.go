@@ -0,0 +1,94 @@
+package pathwell
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// CallOption adjusts a single Call or CallContext without changing the
+// client's configuration
+type CallOption func(*callOptions)
+
+// callOptions is the per-call configuration built from CallOptions
+type callOptions struct {
+	headers    map[string]string
+	timeout    time.Duration
+	hasTimeout bool
+	mutators   []func(req *http.Request) error
+}
+
+// WithHeader sets a header on this call, overriding the same header in the
+// headers map and DefaultHeaders
+func WithHeader(name, value string) CallOption {
+	return func(o *callOptions) {
+		o.headers = withHeaderReplaced(o.headers, name, value)
+	}
+}
+
+// WithTimeout bounds this call by timeout instead of the 30s default, as
+// CallWithTimeout does. Zero applies no deadline of its own. A caller's
+// HTTPClient.Timeout still applies, so it can only shorten that.
+func WithTimeout(timeout time.Duration) CallOption {
+	return func(o *callOptions) {
+		o.timeout = timeout
+		o.hasTimeout = true
+	}
+}
+
+// WithIdempotencyKey sends key as the Idempotency-Key header of this call,
+// covered by the signature. With IdempotencyKeys set it replaces the
+// generated key.
+func WithIdempotencyKey(key string) CallOption {
+	return WithHeader(IdempotencyKeyHeader, key)
+}
+
+// WithRequestMutator runs fn on every attempt of this call just before it is
+// signed, after the client's PreSign hook. Like PreSign, it sees the final
+// headers and may change them, but not the method, URL or body.
+func WithRequestMutator(fn func(req *http.Request) error) CallOption {
+	return func(o *callOptions) {
+		o.mutators = append(o.mutators, fn)
+	}
+}
+
+// applyCallOptions folds opts into a callOptions, starting from the headers
+// passed to the call
+func applyCallOptions(headers map[string]string, opts []CallOption) callOptions {
+	o := callOptions{headers: headers}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// callMutatorsKey is the context key for the request mutators of a call
+type callMutatorsKey struct{}
+
+// withCallMutators attaches mutators to ctx for newSignedRequest to run
+func withCallMutators(ctx context.Context, mutators []func(req *http.Request) error) context.Context {
+	if len(mutators) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, callMutatorsKey{}, mutators)
+}
+
+// callMutatorsFromContext returns the request mutators in ctx, if any
+func callMutatorsFromContext(ctx context.Context) []func(req *http.Request) error {
+	mutators, _ := ctx.Value(callMutatorsKey{}).([]func(req *http.Request) error)
+	return mutators
+}
+
+// withHeaderReplaced returns a copy of headers with name set to value and
+// any differently cased spelling of name removed
+func withHeaderReplaced(headers map[string]string, name, value string) map[string]string {
+	canonical := http.CanonicalHeaderKey(name)
+	merged := make(map[string]string, len(headers)+1)
+	for k, v := range headers {
+		if http.CanonicalHeaderKey(k) != canonical {
+			merged[k] = v
+		}
+	}
+	merged[name] = value
+	return merged
+}
@@ -0,0 +1,34 @@
+package pathwell_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pathwell/connect-go/pathwell"
+	"github.com/pathwell/connect-go/pathwell/pathwelltest"
+)
+
+func TestWithTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	_, client := newProxyClient(t, pathwelltest.ProxyOptions{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		}),
+	}, pathwell.ClientOptions{TargetURL: "https://api.example.com"})
+
+	start := time.Now()
+	_, err := client.Call("GET", "/slow", nil, nil, pathwell.WithTimeout(50*time.Millisecond))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("call took %s, WithTimeout was not applied", elapsed)
+	}
+}
@@ -0,0 +1,152 @@
+package pathwell
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// upperHex is the digit set for percent-encoding
+const upperHex = "0123456789ABCDEF"
+
+// CanonicalPath returns the canonical form of a request path and query, as
+// covered by request signatures. Both SignRequest and VerifySignature apply
+// it, so client and server agree regardless of how the path was encoded:
+//
+//   - each path segment is percent-decoded and re-encoded so that only
+//     unreserved characters (A-Z a-z 0-9 - . _ ~) stay literal, using
+//     uppercase hex; encoded slashes (%2F) stay encoded
+//   - an empty path becomes "/"; trailing slashes are preserved
+//   - query parameters are decoded, re-encoded the same way and sorted by
+//     name, then value; an empty query is dropped along with its "?"
+func CanonicalPath(path string) string {
+	rawPath, rawQuery, _ := strings.Cut(path, "?")
+
+	segments := strings.Split(rawPath, "/")
+	for i, segment := range segments {
+		if decoded, err := url.PathUnescape(segment); err == nil {
+			segment = decoded
+		}
+		segments[i] = escapeCanonical(segment)
+	}
+	canonical := strings.Join(segments, "/")
+	if canonical == "" {
+		canonical = "/"
+	}
+
+	if query := canonicalQuery(rawQuery); query != "" {
+		canonical += "?" + query
+	}
+	return canonical
+}
+
+// canonicalQuery decodes, re-encodes and sorts the parameters of a raw query
+func canonicalQuery(rawQuery string) string {
+	type param struct{ key, value string }
+
+	var params []param
+	for _, pair := range strings.Split(rawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(pair, "=")
+		if decoded, err := url.QueryUnescape(key); err == nil {
+			key = decoded
+		}
+		if decoded, err := url.QueryUnescape(value); err == nil {
+			value = decoded
+		}
+		params = append(params, param{escapeCanonical(key), escapeCanonical(value)})
+	}
+
+	sort.Slice(params, func(i, j int) bool {
+		if params[i].key != params[j].key {
+			return params[i].key < params[j].key
+		}
+		return params[i].value < params[j].value
+	})
+
+	pairs := make([]string, len(params))
+	for i, p := range params {
+		pairs[i] = p.key + "=" + p.value
+	}
+	return strings.Join(pairs, "&")
+}
+
+// escapeRawQuery percent-encodes the bytes of a raw query that cannot appear
+// on the request line (spaces, control characters and non-ASCII), leaving
+// existing escapes and separators untouched
+func escapeRawQuery(rawQuery string) string {
+	var b strings.Builder
+	for i := 0; i < len(rawQuery); i++ {
+		c := rawQuery[i]
+		if c > ' ' && c < 0x7F {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(upperHex[c>>4])
+		b.WriteByte(upperHex[c&0x0F])
+	}
+	return b.String()
+}
+
+// escapeCanonical percent-encodes every byte except unreserved characters
+func escapeCanonical(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if isUnreserved(c) {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(upperHex[c>>4])
+		b.WriteByte(upperHex[c&0x0F])
+	}
+	return b.String()
+}
+
+// isUnreserved reports whether c is an RFC 3986 unreserved character
+func isUnreserved(c byte) bool {
+	return 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+// CanonicalHost returns the canonical form of an upstream origin, as covered
+// by request signatures when IncludeHostInSignature is set:
+//
+//   - scheme and host are lowercased, and a trailing dot on the host is
+//     dropped
+//   - the port is dropped when it is the scheme's default (80 for http, 443
+//     for https) and kept otherwise
+//   - IPv6 addresses stay in brackets
+//   - any userinfo, path, query or fragment is ignored
+//
+// so "HTTPS://API.Example.com:443/v1" becomes "https://api.example.com".
+func CanonicalHost(rawURL string) (string, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid host URL %q: %w", rawURL, err)
+	}
+	if parsed.Scheme == "" || parsed.Host == "" {
+		return "", fmt.Errorf("invalid host URL %q: scheme and host are required", rawURL)
+	}
+
+	scheme := strings.ToLower(parsed.Scheme)
+	host := strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+	port := parsed.Port()
+	if scheme == "http" && port == "80" || scheme == "https" && port == "443" {
+		port = ""
+	}
+
+	if port != "" {
+		return scheme + "://" + net.JoinHostPort(host, port), nil
+	}
+	if strings.Contains(host, ":") {
+		return scheme + "://[" + host + "]", nil
+	}
+	return scheme + "://" + host, nil
+}
@@ -0,0 +1,70 @@
+package pathwell_test
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/pathwell/connect-go/pathwell"
+	"github.com/pathwell/connect-go/pathwell/pathwelltest"
+)
+
+func TestCanonicalPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"", "/"},
+		{"/", "/"},
+		{"/v1/chat/", "/v1/chat/"},
+		{"/files/my file.txt", "/files/my%20file.txt"},
+		{"/files/my%20file.txt", "/files/my%20file.txt"},
+		{"/files/my+file.txt", "/files/my%2Bfile.txt"},
+		{"/users/j%c3%bcrgen", "/users/j%C3%BCrgen"},
+		{"/users/jürgen", "/users/j%C3%BCrgen"},
+		{"/users/%7Ejane", "/users/~jane"},
+		{"/docs/a%2Fb", "/docs/a%2Fb"},
+		{"/docs/a%3Fb%23c", "/docs/a%3Fb%23c"},
+		{"/docs/a:b@c", "/docs/a%3Ab%40c"},
+		{"/search?q=hello world", "/search?q=hello%20world"},
+		{"/search?q=hello+world", "/search?q=hello%20world"},
+		{"/search?b=2&a=1&a=0", "/search?a=0&a=1&b=2"},
+		{"/search?q=a%26b&r=%3D", "/search?q=a%26b&r=%3D"},
+		{"/search?name=%C3%A9t%C3%A9", "/search?name=%C3%A9t%C3%A9"},
+		{"/search?", "/search"},
+		{"/bad%zz", "/bad%25zz"},
+	}
+	for _, tt := range tests {
+		if got := pathwell.CanonicalPath(tt.path); got != tt.want {
+			t.Errorf("CanonicalPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+// Paths whose encoding net/http may change on the way still verify at the
+// proxy, which canonicalizes what it received
+func TestCanonicalPathSurvivesTransport(t *testing.T) {
+	proxy, client := newProxyClient(t, pathwelltest.ProxyOptions{}, pathwell.ClientOptions{})
+	for _, path := range []string{
+		"https://api.example.com/files/my file.txt",
+		"https://api.example.com/users/jürgen",
+		"https://api.example.com/users/%7Ejane",
+		"https://api.example.com/docs/a%2Fb",
+		"https://api.example.com/docs/a:b@c",
+		"https://api.example.com/search?q=hello world&lang=日本",
+		"https://api.example.com/search?q=a%26b&r=%3D",
+	} {
+		resp, err := client.Get(path, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("%s: status %d", path, resp.StatusCode)
+		}
+	}
+	if n := len(proxy.Requests()); n != 7 {
+		t.Fatalf("proxy saw %d requests", n)
+	}
+}
@@ -0,0 +1,419 @@
+package pathwell_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pathwell/connect-go/pathwell"
+	"github.com/pathwell/connect-go/pathwell/pathwelltest"
+)
+
+// newProxyClient starts a TestProxy and a client signing for it
+func newProxyClient(t *testing.T, proxyOptions pathwelltest.ProxyOptions, options pathwell.ClientOptions) (*pathwelltest.TestProxy, *pathwell.Client) {
+	t.Helper()
+	keyPair, err := pathwell.GenerateKeyPairWithAlgorithm(pathwell.Ed25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxy := pathwelltest.NewTestProxy(keyPair.PublicKey, proxyOptions)
+	t.Cleanup(proxy.Close)
+
+	if options.AgentID == "" {
+		options.AgentID = "agent-123"
+	}
+	options.PrivateKeyPEM = keyPair.PrivateKey
+	options.ProxyURL = proxy.URL
+	client, err := pathwell.NewClient(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return proxy, client
+}
+
+// newStubClient returns a client sending to a stub server at proxyURL that
+// does not verify signatures
+func newStubClient(t *testing.T, proxyURL string, options pathwell.ClientOptions) *pathwell.Client {
+	t.Helper()
+	keyPair, err := pathwell.GenerateKeyPairWithAlgorithm(pathwell.Ed25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if options.AgentID == "" {
+		options.AgentID = "agent-123"
+	}
+	options.PrivateKeyPEM = keyPair.PrivateKey
+	options.ProxyURL = proxyURL
+	client, err := pathwell.NewClient(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+// TestClientConcurrentUse shares one client between many goroutines while
+// its key is reloaded, with the features that keep state after NewClient
+// enabled. Run it with -race.
+func TestClientConcurrentUse(t *testing.T) {
+	keyPair, err := pathwell.GenerateKeyPairWithAlgorithm(pathwell.Ed25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxy := pathwelltest.NewTestProxy(keyPair.PublicKey, pathwelltest.ProxyOptions{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: r.Header.Get(pathwell.DefaultNonceHeader)})
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"ok":true}`))
+		}),
+	})
+	defer proxy.Close()
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var skewReports atomic.Int64
+	client, err := pathwell.NewClient(pathwell.ClientOptions{
+		AgentID:                 "agent-123",
+		PrivateKeyPEM:           keyPair.PrivateKey,
+		ProxyURL:                proxy.URL,
+		BaseURL:                 "https://api.example.com/v1/",
+		KeyID:                   "key-0",
+		CookieJar:               jar,
+		CoalesceGETs:            true,
+		AutoCorrectSkew:         true,
+		OnClockSkew:             func(skew time.Duration) { skewReports.Add(1) },
+		CircuitBreakerThreshold: 100,
+		RateLimit:               1e6,
+		RateLimitBurst:          1000,
+		IdempotencyKeys:         true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const goroutines, callsEach = 32, 20
+	var wg sync.WaitGroup
+	errs := make(chan error, goroutines*callsEach)
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < callsEach; i++ {
+				method, body := "GET", interface{}(nil)
+				if i%2 == 1 {
+					method, body = "POST", map[string]int{"g": g, "i": i}
+				}
+				resp, err := client.Call(method, fmt.Sprintf("items/%d", i%4), nil, body)
+				if err != nil {
+					errs <- err
+					continue
+				}
+				resp.Body.Close()
+				if resp.StatusCode != http.StatusOK {
+					errs <- fmt.Errorf("%s items/%d: status %d", method, i%4, resp.StatusCode)
+				}
+			}
+		}(g)
+	}
+
+	// Rotate the key ID underneath the calls; the proxy verifies against the
+	// same public key, so every request must still pass
+	stop := make(chan struct{})
+	reloaded := make(chan struct{})
+	go func() {
+		defer close(reloaded)
+		for n := 1; ; n++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			if err := client.ReloadKey(keyPair.PrivateKey, fmt.Sprintf("key-%d", n)); err != nil {
+				errs <- err
+				return
+			}
+			if _, err := client.PublicKeyPEM(); err != nil {
+				errs <- err
+				return
+			}
+		}
+	}()
+
+	wg.Wait()
+	close(stop)
+	<-reloaded
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+	if len(proxy.Requests()) == 0 {
+		t.Fatal("no requests reached the proxy")
+	}
+}
+
+func TestProxyURL(t *testing.T) {
+	t.Setenv(pathwell.ProxyURLEnv, "")
+
+	valid := []struct {
+		proxyURL string
+		want     string
+	}{
+		{"", "http://localhost:8080/v1/chat"},
+		{"http://proxy.internal:8080", "http://proxy.internal:8080/v1/chat"},
+		{"http://proxy.internal:8080/", "http://proxy.internal:8080/v1/chat"},
+		{"https://proxy.internal//", "https://proxy.internal/v1/chat"},
+	}
+	for _, tt := range valid {
+		client := newStubClient(t, tt.proxyURL, pathwell.ClientOptions{})
+		req, err := client.BuildSignedRequest("GET", "https://api.example.com/v1/chat", nil, nil)
+		if err != nil {
+			t.Errorf("ProxyURL %q: %v", tt.proxyURL, err)
+			continue
+		}
+		if got := req.URL.String(); got != tt.want {
+			t.Errorf("ProxyURL %q: request URL = %q, want %q", tt.proxyURL, got, tt.want)
+		}
+	}
+
+	keyPair, err := pathwell.GenerateKeyPairWithAlgorithm(pathwell.Ed25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, proxyURL := range []string{"/", "localhost:8080", "proxy.internal", "ftp://proxy.internal", "http://", "http://proxy internal"} {
+		_, err := pathwell.NewClient(pathwell.ClientOptions{
+			AgentID:       "agent-123",
+			PrivateKeyPEM: keyPair.PrivateKey,
+			ProxyURL:      proxyURL,
+		})
+		if err == nil {
+			t.Errorf("ProxyURL %q accepted", proxyURL)
+		}
+	}
+}
+
+// Methods are sent and signed uppercase whatever case the caller used, so
+// the proxy's verification and idempotency rules see the canonical form
+func TestMixedCaseMethods(t *testing.T) {
+	proxy, client := newProxyClient(t, pathwelltest.ProxyOptions{}, pathwell.ClientOptions{
+		TargetURL:       "https://api.example.com",
+		IdempotencyKeys: true,
+	})
+
+	for _, method := range []string{"get", "Post", "pAtCh", "delete"} {
+		resp, err := client.Call(method, "/x", nil, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", method, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("%s: status %d", method, resp.StatusCode)
+		}
+	}
+
+	requests := proxy.Requests()
+	for i, want := range []string{"GET", "POST", "PATCH", "DELETE"} {
+		if requests[i].Method != want {
+			t.Errorf("request %d sent as %q, want %q", i, requests[i].Method, want)
+		}
+		hasKey := requests[i].Header.Get(pathwell.IdempotencyKeyHeader) != ""
+		if wantKey := want == "POST" || want == "PATCH"; hasKey != wantKey {
+			t.Errorf("%s: Idempotency-Key sent = %v, want %v", want, hasKey, wantKey)
+		}
+	}
+
+	if pathwell.CanonicalPayload("post", "/x", nil, "1", "n") != pathwell.CanonicalPayload("POST", "/x", nil, "1", "n") {
+		t.Error("CanonicalPayload depends on method case")
+	}
+}
+
+// The key ID travels in its header and in the signed payload, so the proxy
+// rejects a request whose key ID was swapped in transit
+func TestKeyIDSentAndSigned(t *testing.T) {
+	keyPair, err := pathwell.GenerateKeyPairWithAlgorithm(pathwell.Ed25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxy := pathwelltest.NewTestProxy(keyPair.PublicKey, pathwelltest.ProxyOptions{})
+	defer proxy.Close()
+
+	var swapKeyID atomic.Bool
+	client, err := pathwell.NewClient(pathwell.ClientOptions{
+		AgentID:       "agent-123",
+		PrivateKeyPEM: keyPair.PrivateKey,
+		KeyID:         "key-1",
+		ProxyURL:      proxy.URL,
+		TargetURL:     "https://api.example.com",
+		Middlewares: []pathwell.Middleware{func(next http.RoundTripper) http.RoundTripper {
+			return pathwell.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				if swapKeyID.Load() {
+					req.Header.Set(pathwell.DefaultKeyIDHeader, "key-0")
+				}
+				return next.RoundTrip(req)
+			})
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	get := func() int {
+		t.Helper()
+		resp, err := client.Get("/x", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if status := get(); status != http.StatusOK {
+		t.Fatalf("status %d", status)
+	}
+	if err := client.ReloadKey(keyPair.PrivateKey, "key-2"); err != nil {
+		t.Fatal(err)
+	}
+	if status := get(); status != http.StatusOK {
+		t.Fatalf("status %d after reload", status)
+	}
+	swapKeyID.Store(true)
+	if status := get(); status != http.StatusUnauthorized {
+		t.Fatalf("status %d with a swapped key ID, want 401", status)
+	}
+
+	requests := proxy.Requests()
+	for i, want := range []string{"key-1", "key-2"} {
+		if got := requests[i].Header.Get(pathwell.DefaultKeyIDHeader); got != want {
+			t.Errorf("request %d key ID = %q, want %q", i, got, want)
+		}
+	}
+
+	if err := client.ReloadKey(keyPair.PrivateKey, "key-3\r\nX-Evil: 1"); err == nil {
+		t.Error("ReloadKey accepted a key ID with a line break")
+	}
+}
+
+func TestNoKeyIDHeaderWithoutKeyID(t *testing.T) {
+	proxy, client := newProxyClient(t, pathwelltest.ProxyOptions{}, pathwell.ClientOptions{TargetURL: "https://api.example.com"})
+	resp, err := client.Get("/x", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if _, ok := proxy.Requests()[0].Header[pathwell.DefaultKeyIDHeader]; ok {
+		t.Error("key ID header sent without a KeyID")
+	}
+}
+
+func TestAgentIDValidation(t *testing.T) {
+	t.Setenv(pathwell.AgentIDEnv, "")
+	keyPair, err := pathwell.GenerateKeyPairWithAlgorithm(pathwell.Ed25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newClient := func(agentID string) (*pathwell.Client, error) {
+		return pathwell.NewClient(pathwell.ClientOptions{AgentID: agentID, PrivateKeyPEM: keyPair.PrivateKey})
+	}
+
+	for _, agentID := range []string{
+		"",
+		"   ",
+		"agent\r\nX-Evil: 1",
+		"agent\nX-Evil: 1",
+		"agent\rX-Evil: 1",
+		"agent\x00",
+		"agent\tone",
+		"agënt",
+		strings.Repeat("a", 257),
+	} {
+		if _, err := newClient(agentID); err == nil {
+			t.Errorf("AgentID %q accepted", agentID)
+		}
+	}
+
+	for agentID, want := range map[string]string{
+		" agent-123\t":           "agent-123",
+		"org/agent:1@prod":       "org/agent:1@prod",
+		strings.Repeat("a", 256): strings.Repeat("a", 256),
+	} {
+		var got string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r.Header.Get(pathwell.DefaultAgentIDHeader)
+		}))
+		client := newStubClient(t, server.URL, pathwell.ClientOptions{AgentID: agentID})
+		resp, err := client.Get("https://api.example.com/x", nil)
+		server.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if got != want {
+			t.Errorf("AgentID %q sent as %q, want %q", agentID, got, want)
+		}
+	}
+}
+
+func TestHead(t *testing.T) {
+	type seen struct {
+		method        string
+		contentLength int64
+		chunked       bool
+		body          int
+	}
+	var mu sync.Mutex
+	var got seen
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, _ := io.Copy(io.Discard, r.Body)
+		mu.Lock()
+		got = seen{r.Method, r.ContentLength, len(r.TransferEncoding) > 0, int(n)}
+		mu.Unlock()
+		w.Header().Set("Content-Length", "1234")
+		w.Header().Set("ETag", `"v1"`)
+	}))
+	defer server.Close()
+
+	client := newStubClient(t, server.URL, pathwell.ClientOptions{IdempotencyKeys: true})
+	resp, err := client.Head("https://api.example.com/files/1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	mu.Lock()
+	defer mu.Unlock()
+	if got != (seen{method: "HEAD"}) {
+		t.Fatalf("proxy saw %+v, want a HEAD without a body", got)
+	}
+	if resp.ContentLength != 1234 || resp.Header.Get("ETag") != `"v1"` {
+		t.Fatalf("response headers %v, Content-Length %d", resp.Header, resp.ContentLength)
+	}
+	if n, _ := io.Copy(io.Discard, resp.Body); n != 0 {
+		t.Fatalf("read %d body bytes from a HEAD response", n)
+	}
+}
+
+// HEAD is signed like a GET, with an empty body hash, and verifies
+func TestHeadVerifies(t *testing.T) {
+	proxy, client := newProxyClient(t, pathwelltest.ProxyOptions{}, pathwell.ClientOptions{
+		TargetURL:       "https://api.example.com",
+		IdempotencyKeys: true,
+	})
+	resp, err := client.Head("/files/1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status %d", resp.StatusCode)
+	}
+	r := proxy.Requests()[0]
+	if r.Method != "HEAD" || len(r.Body) != 0 || r.Header.Get(pathwell.IdempotencyKeyHeader) != "" {
+		t.Fatalf("proxy saw %s with %d body bytes, Idempotency-Key %q", r.Method, len(r.Body), r.Header.Get(pathwell.IdempotencyKeyHeader))
+	}
+}
@@ -0,0 +1,48 @@
+package pathwell
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// cookieURL returns the URL the cookies of a request are scoped to: the
+// upstream URL when the request has a target, so upstream session cookies
+// keep their own domain, and the proxy URL otherwise
+func (c *Client) cookieURL(prepared *preparedRequest) (*url.URL, error) {
+	if prepared.target != "" {
+		return url.Parse(prepared.target + strings.TrimPrefix(prepared.path, c.pathPrefix))
+	}
+	return url.Parse(c.proxyURL + prepared.path)
+}
+
+// addCookies adds the jar's cookies for prepared to req, after any Cookie
+// header passed in the call
+func (c *Client) addCookies(req *http.Request, prepared *preparedRequest) {
+	if c.cookieJar == nil {
+		return
+	}
+	u, err := c.cookieURL(prepared)
+	if err != nil {
+		return
+	}
+	for _, cookie := range c.cookieJar.Cookies(u) {
+		req.AddCookie(cookie)
+	}
+}
+
+// storeCookies saves the cookies set by resp in the jar
+func (c *Client) storeCookies(resp *http.Response, prepared *preparedRequest) {
+	if c.cookieJar == nil {
+		return
+	}
+	cookies := resp.Cookies()
+	if len(cookies) == 0 {
+		return
+	}
+	u, err := c.cookieURL(prepared)
+	if err != nil {
+		return
+	}
+	c.cookieJar.SetCookies(u, cookies)
+}
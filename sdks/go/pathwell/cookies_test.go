@@ -0,0 +1,60 @@
+package pathwell_test
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pathwell/connect-go/pathwell"
+)
+
+// sessionHandler sets a session cookie on /login and requires it elsewhere
+func sessionHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "s-42", Path: "/"})
+			return
+		}
+		if cookie, err := r.Cookie("session"); err != nil || cookie.Value != "s-42" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	})
+}
+
+func TestCookieJar(t *testing.T) {
+	server := httptest.NewServer(sessionHandler())
+	defer server.Close()
+
+	status := func(client *pathwell.Client, url string) int {
+		t.Helper()
+		resp, err := client.Get(url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newStubClient(t, server.URL, pathwell.ClientOptions{CookieJar: jar})
+	if code := status(client, "https://api.example.com/login"); code != http.StatusOK {
+		t.Fatalf("login: status %d", code)
+	}
+	if code := status(client, "https://api.example.com/me"); code != http.StatusOK {
+		t.Fatalf("status %d, want the session cookie sent back", code)
+	}
+	// Cookies are scoped to the upstream that set them
+	if code := status(client, "https://billing.example.com/me"); code != http.StatusUnauthorized {
+		t.Fatalf("status %d, want the cookie withheld from another upstream", code)
+	}
+
+	withoutJar := newStubClient(t, server.URL, pathwell.ClientOptions{})
+	status(withoutJar, "https://api.example.com/login")
+	if code := status(withoutJar, "https://api.example.com/me"); code != http.StatusUnauthorized {
+		t.Fatalf("status %d without a jar, want 401", code)
+	}
+}
@@ -0,0 +1,108 @@
+package pathwell
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// defaultAPIKeyHeader carries APIKey when APIKeyHeader is unset
+const defaultAPIKeyHeader = "X-API-Key"
+
+// credential is an upstream credential header forwarded on every request
+type credential struct {
+	name  string
+	value string
+}
+
+// newCredentials resolves the BearerToken and APIKey options into headers
+func newCredentials(options ClientOptions) ([]credential, error) {
+	var credentials []credential
+	if options.BearerToken != "" {
+		credentials = append(credentials, credential{name: "Authorization", value: "Bearer " + options.BearerToken})
+	}
+	if options.APIKey != "" {
+		name := http.CanonicalHeaderKey(orDefault(options.APIKeyHeader, defaultAPIKeyHeader))
+		credentials = append(credentials, credential{name: name, value: options.APIKey})
+	}
+
+	for _, cred := range credentials {
+		if strings.ContainsAny(cred.value, "\r\n") {
+			return nil, fmt.Errorf("invalid %s credential: must not contain line breaks", cred.name)
+		}
+	}
+	return credentials, nil
+}
+
+// resolveSignedHeaders returns the canonical names of the headers to sign:
+// SignedHeaders, plus the credential headers when SignCredentials is set.
+// Pathwell's own headers, under default or custom names, cannot be signed.
+func resolveSignedHeaders(options ClientOptions, credentials []credential) ([]string, error) {
+	reserved := newHeaderNames(options)
+	seen := make(map[string]bool)
+	var names []string
+	add := func(name string) {
+		name = http.CanonicalHeaderKey(name)
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	for _, name := range options.SignedHeaders {
+		if name == "" || strings.ContainsAny(name, " :;\r\n") {
+			return nil, fmt.Errorf("invalid signed header name %q", name)
+		}
+		if reserved.isReserved(name) {
+			return nil, fmt.Errorf("invalid signed header %q: Pathwell headers cannot be signed", name)
+		}
+		add(name)
+	}
+	if options.SignCredentials {
+		for _, cred := range credentials {
+			add(cred.name)
+		}
+	}
+	return names, nil
+}
+
+// isCredentialHeader reports whether name carries one of credentials
+func isCredentialHeader(credentials []credential, name string) bool {
+	name = http.CanonicalHeaderKey(name)
+	for _, cred := range credentials {
+		if cred.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// signedHeaderLines renders headers as the "name:value" lines appended to
+// the signed payload, with lowercase names in sorted order
+func signedHeaderLines(headers map[string]string) string {
+	names := make([]string, 0, len(headers))
+	values := make(map[string]string, len(headers))
+	for name, value := range headers {
+		name = strings.ToLower(name)
+		names = append(names, name)
+		values[name] = strings.TrimSpace(value)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString("\n" + name + ":" + values[name])
+	}
+	return b.String()
+}
+
+// signedHeaderNames returns the value of X-Pathwell-Signed-Headers for headers
+func signedHeaderNames(headers map[string]string) string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, strings.ToLower(name))
+	}
+	sort.Strings(names)
+	return strings.Join(names, ";")
+}
@@ -0,0 +1,197 @@
+package pathwell
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// debugEnv enables the debug dump when set to a true value such as "1"
+const debugEnv = "PATHWELL_DEBUG"
+
+// defaultDebugMaxBodyBytes is how much of each body the debug dump prints
+// when DebugMaxBodyBytes is unset
+const defaultDebugMaxBodyBytes = 4096
+
+// debugEnabled reports whether the debug dump is on, by option or by
+// PATHWELL_DEBUG
+func debugEnabled(options ClientOptions) bool {
+	if options.Debug {
+		return true
+	}
+	enabled, _ := strconv.ParseBool(os.Getenv(debugEnv))
+	return enabled
+}
+
+// debugDumper writes each request and response as sent and received
+type debugDumper struct {
+	mu      sync.Mutex
+	w       io.Writer
+	redact  []string
+	maxBody int
+}
+
+// newDebugMiddleware returns the middleware that dumps every exchange to w,
+// with the named headers redacted
+func newDebugMiddleware(w io.Writer, redact []string, maxBody int) Middleware {
+	if w == nil {
+		w = os.Stderr
+	}
+	if maxBody <= 0 {
+		maxBody = defaultDebugMaxBodyBytes
+	}
+	d := &debugDumper{w: w, redact: redact, maxBody: maxBody}
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			var b strings.Builder
+			d.dumpRequest(&b, req)
+			resp, err := next.RoundTrip(req)
+			if err != nil {
+				fmt.Fprintf(&b, "<<< error: %v\n", err)
+			} else {
+				d.dumpResponse(&b, resp)
+			}
+
+			// One write per exchange keeps concurrent calls from interleaving
+			d.mu.Lock()
+			io.WriteString(d.w, b.String())
+			d.mu.Unlock()
+			return resp, err
+		})
+	}
+}
+
+// dumpRequest writes the request line, redacted headers and body preview.
+// Only replayable bodies are read; streamed ones are summarized.
+func (d *debugDumper) dumpRequest(b *strings.Builder, req *http.Request) {
+	clone := req.Clone(req.Context())
+	clone.Header = redactHeaders(req.Header, d.redact)
+	if req.Body != nil && req.Body != http.NoBody {
+		// A stand-in the dump never reads, so the real body is left alone
+		clone.Body = io.NopCloser(strings.NewReader(""))
+	}
+	head, err := httputil.DumpRequestOut(clone, false)
+	if err != nil {
+		fmt.Fprintf(b, ">>> %s %s (dump failed: %v)\n", req.Method, req.URL, err)
+		return
+	}
+	writePrefixed(b, ">>> ", head)
+
+	switch {
+	case req.Body == nil || req.Body == http.NoBody:
+	case req.GetBody != nil:
+		body, err := req.GetBody()
+		if err != nil {
+			return
+		}
+		data, _ := io.ReadAll(io.LimitReader(body, int64(d.maxBody)+1))
+		body.Close()
+		d.writeBody(b, ">>> ", data, req.ContentLength, req.Header)
+	default:
+		fmt.Fprintf(b, ">>> [streamed body, %s]\n", describeLength(req.ContentLength))
+	}
+}
+
+// dumpResponse writes the status line, redacted headers and body preview.
+// The previewed bytes are put back so the caller still reads the full body.
+func (d *debugDumper) dumpResponse(b *strings.Builder, resp *http.Response) {
+	clone := *resp
+	clone.Header = redactHeaders(resp.Header, d.redact)
+	clone.Body = nil
+	head, err := httputil.DumpResponse(&clone, false)
+	if err != nil {
+		fmt.Fprintf(b, "<<< %s (dump failed: %v)\n", resp.Status, err)
+		return
+	}
+	writePrefixed(b, "<<< ", head)
+
+	if resp.Body == nil || resp.Body == http.NoBody {
+		return
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, int64(d.maxBody)+1))
+	resp.Body = &replayedBody{Reader: io.MultiReader(bytes.NewReader(data), resp.Body), body: resp.Body}
+	if err != nil {
+		fmt.Fprintf(b, "<<< [body read failed: %v]\n", err)
+		return
+	}
+	d.writeBody(b, "<<< ", data, resp.ContentLength, resp.Header)
+}
+
+// writeBody writes a preview of data, truncated to maxBody bytes, or a
+// one-line summary when the body is compressed or not text
+func (d *debugDumper) writeBody(b *strings.Builder, prefix string, data []byte, length int64, header http.Header) {
+	if len(data) == 0 {
+		return
+	}
+	if header.Get("Content-Encoding") != "" || !isPrintable(data) {
+		contentType := header.Get("Content-Type")
+		if contentType == "" {
+			contentType = "unknown type"
+		}
+		fmt.Fprintf(b, "%s[binary body, %s, %s]\n", prefix, describeLength(length), contentType)
+		return
+	}
+
+	truncated := len(data) > d.maxBody
+	if truncated {
+		data = data[:d.maxBody]
+	}
+	writePrefixed(b, prefix, data)
+	if truncated {
+		fmt.Fprintf(b, "%s[truncated at %d bytes of %s]\n", prefix, d.maxBody, describeLength(length))
+	}
+}
+
+// writePrefixed writes each line of data with prefix in front
+func writePrefixed(b *strings.Builder, prefix string, data []byte) {
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		b.WriteString(prefix)
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+}
+
+// describeLength renders a body length for the debug dump
+func describeLength(length int64) string {
+	if length < 0 {
+		return "unknown length"
+	}
+	return fmt.Sprintf("%d bytes", length)
+}
+
+// isPrintable reports whether data looks like text: valid UTF-8, allowing
+// a rune cut off at the end, without control characters other than
+// whitespace
+func isPrintable(data []byte) bool {
+	for len(data) > 0 {
+		r, size := utf8.DecodeRune(data)
+		if r == utf8.RuneError && size <= 1 {
+			return len(data) < utf8.UTFMax && !utf8.FullRune(data)
+		}
+		if r < ' ' && r != '\n' && r != '\r' && r != '\t' || r == 0x7F {
+			return false
+		}
+		data = data[size:]
+	}
+	return true
+}
+
+// replayedBody serves previewed bytes ahead of the rest of a body and
+// closes the original
+type replayedBody struct {
+	io.Reader
+	body io.ReadCloser
+}
+
+// Close closes the original body
+func (r *replayedBody) Close() error {
+	return r.body.Close()
+}
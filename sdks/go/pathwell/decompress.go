@@ -0,0 +1,66 @@
+package pathwell
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// decompressResponse replaces a gzip or deflate encoded response body with
+// a decoding reader and clears Content-Encoding and Content-Length. Other
+// encodings are left untouched.
+func decompressResponse(resp *http.Response) error {
+	var decoded io.ReadCloser
+	switch strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))) {
+	case "gzip", "x-gzip":
+		reader, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return fmt.Errorf("failed to decompress gzip response: %w", err)
+		}
+		decoded = reader
+	case "deflate":
+		// "deflate" is meant to be zlib-wrapped, but raw deflate is common
+		buffered := bufio.NewReader(resp.Body)
+		header, _ := buffered.Peek(2)
+		if isZlibHeader(header) {
+			reader, err := zlib.NewReader(buffered)
+			if err != nil {
+				return fmt.Errorf("failed to decompress deflate response: %w", err)
+			}
+			decoded = reader
+		} else {
+			decoded = flate.NewReader(buffered)
+		}
+	default:
+		return nil
+	}
+
+	resp.Body = &decodedBody{ReadCloser: decoded, raw: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return nil
+}
+
+// isZlibHeader reports whether b starts with a valid zlib stream header
+func isZlibHeader(b []byte) bool {
+	return len(b) == 2 && b[0]&0x0F == 8 && (uint16(b[0])<<8|uint16(b[1]))%31 == 0
+}
+
+// decodedBody closes both the decompressor and the underlying body
+type decodedBody struct {
+	io.ReadCloser
+	raw io.ReadCloser
+}
+
+// Close closes the decompressor and the raw body
+func (b *decodedBody) Close() error {
+	b.ReadCloser.Close()
+	return b.raw.Close()
+}
@@ -0,0 +1,46 @@
+package pathwell
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+// Headers that carry the request body digest when SendBodyDigestHeader is set
+const (
+	ContentSHA256Header = "X-Content-SHA256"
+	DigestHeader        = "Digest"
+)
+
+// DigestEncoding selects the header SendBodyDigestHeader attaches
+type DigestEncoding int
+
+const (
+	// DigestHex sends X-Content-SHA256 with the lowercase hex digest, the
+	// same string as BODY_HASH in the signed payload
+	DigestHex DigestEncoding = iota
+	// DigestBase64 sends an RFC 3230 Digest header, "SHA-256=<base64>"
+	DigestBase64
+)
+
+// String returns the name of the encoding
+func (e DigestEncoding) String() string {
+	switch e {
+	case DigestHex:
+		return "DigestHex"
+	case DigestBase64:
+		return "DigestBase64"
+	default:
+		return fmt.Sprintf("DigestEncoding(%d)", int(e))
+	}
+}
+
+// bodyDigestHeader returns the header name and value carrying bodyHash, the
+// hex SHA-256 signed for the request body
+func bodyDigestHeader(encoding DigestEncoding, bodyHash string) (string, string) {
+	if encoding == DigestBase64 {
+		digest, _ := hex.DecodeString(bodyHash)
+		return DigestHeader, "SHA-256=" + base64.StdEncoding.EncodeToString(digest)
+	}
+	return ContentSHA256Header, bodyHash
+}
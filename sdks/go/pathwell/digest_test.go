@@ -0,0 +1,164 @@
+package pathwell_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pathwell/connect-go/pathwell"
+)
+
+// digestVerifier is a stub proxy that verifies signatures against the body
+// hash carried in the digest header rather than the body it received, and
+// checks that hash against the body independently
+type digestVerifier struct {
+	publicKey string
+
+	mu      sync.Mutex
+	results []digestResult
+}
+
+type digestResult struct {
+	header    string
+	hash      string
+	bodyHash  string
+	verifyErr error
+}
+
+func (v *digestVerifier) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	result := digestResult{}
+	switch {
+	case r.Header.Get(pathwell.ContentSHA256Header) != "":
+		result.header = pathwell.ContentSHA256Header
+		result.hash = r.Header.Get(pathwell.ContentSHA256Header)
+	case r.Header.Get(pathwell.DigestHeader) != "":
+		result.header = pathwell.DigestHeader
+		digest, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(r.Header.Get(pathwell.DigestHeader), "SHA-256="))
+		result.hash = hex.EncodeToString(digest)
+	}
+	if len(body) > 0 {
+		sum := sha256.Sum256(body)
+		result.bodyHash = hex.EncodeToString(sum[:])
+	}
+
+	var signed map[string]string
+	if names := r.Header.Get(pathwell.DefaultSignedHeadersHeader); names != "" {
+		signed = make(map[string]string)
+		for _, name := range strings.Split(names, ";") {
+			signed[name] = r.Header.Get(name)
+		}
+	}
+	result.verifyErr = pathwell.SignatureInput{
+		Method:    r.Method,
+		Path:      r.URL.RequestURI(),
+		Timestamp: r.Header.Get(pathwell.DefaultTimestampHeader),
+		Nonce:     r.Header.Get(pathwell.DefaultNonceHeader),
+		BodyHash:  result.hash,
+		Headers:   signed,
+	}.Verify(v.publicKey, r.Header.Get(pathwell.DefaultSignatureHeader))
+
+	v.mu.Lock()
+	v.results = append(v.results, result)
+	v.mu.Unlock()
+}
+
+func (v *digestVerifier) last() digestResult {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.results[len(v.results)-1]
+}
+
+// newDigestVerifier starts a digestVerifier and returns it with a client
+// whose options are completed to sign for it
+func newDigestVerifier(t *testing.T, options pathwell.ClientOptions) (*digestVerifier, *pathwell.Client) {
+	t.Helper()
+	keyPair, err := pathwell.GenerateKeyPairWithAlgorithm(pathwell.Ed25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier := &digestVerifier{publicKey: keyPair.PublicKey}
+	server := httptest.NewServer(verifier)
+	t.Cleanup(server.Close)
+
+	options.AgentID = "agent-123"
+	options.PrivateKeyPEM = keyPair.PrivateKey
+	options.ProxyURL = server.URL
+	client, err := pathwell.NewClient(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return verifier, client
+}
+
+func TestBodyDigestHeaderMatchesSignedHash(t *testing.T) {
+	tests := []struct {
+		name       string
+		options    pathwell.ClientOptions
+		wantHeader string
+	}{
+		{"hex", pathwell.ClientOptions{SendBodyDigestHeader: true}, pathwell.ContentSHA256Header},
+		{"base64", pathwell.ClientOptions{SendBodyDigestHeader: true, BodyDigestEncoding: pathwell.DigestBase64}, pathwell.DigestHeader},
+		{"compressed", pathwell.ClientOptions{SendBodyDigestHeader: true, CompressRequestBody: true, CompressMinSize: 1}, pathwell.ContentSHA256Header},
+		{"signed digest", pathwell.ClientOptions{SendBodyDigestHeader: true, SignedHeaders: []string{pathwell.ContentSHA256Header}}, pathwell.ContentSHA256Header},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier, client := newDigestVerifier(t, tt.options)
+			resp, err := client.Post("https://api.example.com/items", nil, map[string]string{"name": strings.Repeat("widget ", 100)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			got := verifier.last()
+			if got.header != tt.wantHeader {
+				t.Fatalf("digest sent in %q, want %q", got.header, tt.wantHeader)
+			}
+			if got.hash != got.bodyHash {
+				t.Errorf("digest %s does not match the body's SHA-256 %s", got.hash, got.bodyHash)
+			}
+			if got.verifyErr != nil {
+				t.Errorf("signature does not cover the digest: %v", got.verifyErr)
+			}
+		})
+	}
+}
+
+func TestBodyDigestHeaderStreamed(t *testing.T) {
+	verifier, client := newDigestVerifier(t, pathwell.ClientOptions{SendBodyDigestHeader: true})
+
+	body := strings.Repeat("chunk ", 1000)
+	sum := sha256.Sum256([]byte(body))
+	resp, err := client.CallWithHashedBody(context.Background(), "PUT", "https://api.example.com/blob", nil,
+		strings.NewReader(body), -1, strings.ToUpper(hex.EncodeToString(sum[:])))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	got := verifier.last()
+	if got.hash != got.bodyHash || got.verifyErr != nil {
+		t.Fatalf("digest %s, body %s, verify %v", got.hash, got.bodyHash, got.verifyErr)
+	}
+}
+
+func TestNoBodyDigestHeaderForEmptyBody(t *testing.T) {
+	verifier, client := newDigestVerifier(t, pathwell.ClientOptions{SendBodyDigestHeader: true})
+
+	resp, err := client.Get("https://api.example.com/items", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if got := verifier.last(); got.header != "" || got.verifyErr != nil {
+		t.Fatalf("digest header %q sent for an empty body, verify %v", got.header, got.verifyErr)
+	}
+}
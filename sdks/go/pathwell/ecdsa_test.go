@@ -0,0 +1,153 @@
+package pathwell_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pathwell/connect-go/pathwell"
+)
+
+// readFixture returns a file from testdata
+func readFixture(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// stdlibECPublicKey parses a PKIX P-256 public key with crypto/x509 alone
+func stdlibECPublicKey(t *testing.T, publicKeyPEM string) *ecdsa.PublicKey {
+	t.Helper()
+	block, _ := pem.Decode([]byte(publicKeyPEM))
+	if block == nil {
+		t.Fatal("no PEM block")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key.(*ecdsa.PublicKey)
+}
+
+const (
+	interopTimestamp = "1700000000"
+	interopNonce     = "0123456789abcdef0123456789abcdef"
+)
+
+var interopBody = []byte(`{"message":"hello"}`)
+
+// The SDK's ECDSA signatures are ASN.1 DER over SHA-256 of the payload, so
+// the standard library verifies them without any SDK code
+func TestECDSASignatureVerifiesWithStdlib(t *testing.T) {
+	publicKey := stdlibECPublicKey(t, readFixture(t, "ec_p256_public.pem"))
+	payload := pathwell.CanonicalPayload("POST", "/v1/chat", interopBody, interopTimestamp, interopNonce)
+	digest := sha256.Sum256([]byte(payload))
+
+	for _, name := range []string{"ec_p256_sec1.pem", "ec_p256_pkcs8.pem"} {
+		signature, err := pathwell.SignRequest(readFixture(t, name), "POST", "/v1/chat", interopBody, interopTimestamp, interopNonce)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		sig, err := base64.StdEncoding.DecodeString(signature)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ecdsa.VerifyASN1(publicKey, digest[:], sig) {
+			t.Errorf("%s: ecdsa.VerifyASN1 rejected the SDK signature", name)
+		}
+	}
+}
+
+func TestECDSAStdlibSignatureVerifies(t *testing.T) {
+	block, _ := pem.Decode([]byte(readFixture(t, "ec_p256_sec1.pem")))
+	privateKey, err := x509.ParseECPrivateKey(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := pathwell.CanonicalPayload("GET", "/v1/items?page=2", nil, interopTimestamp, interopNonce)
+	digest := sha256.Sum256([]byte(payload))
+	sig, err := ecdsa.SignASN1(rand.Reader, privateKey, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = pathwell.VerifySignature(readFixture(t, "ec_p256_public.pem"), "GET", "/v1/items?page=2", nil,
+		interopTimestamp, interopNonce, base64.StdEncoding.EncodeToString(sig))
+	if err != nil {
+		t.Fatalf("VerifySignature rejected an ecdsa.SignASN1 signature: %v", err)
+	}
+}
+
+func TestGeneratedECDSAKeyVerifiesWithStdlib(t *testing.T) {
+	keyPair, err := pathwell.GenerateKeyPairWithAlgorithm(pathwell.ECDSAP256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature, err := pathwell.SignRequest(keyPair.PrivateKey, "PUT", "/v1/items/1", interopBody, interopTimestamp, interopNonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, _ := base64.StdEncoding.DecodeString(signature)
+	digest := sha256.Sum256([]byte(pathwell.CanonicalPayload("PUT", "/v1/items/1", interopBody, interopTimestamp, interopNonce)))
+	if !ecdsa.VerifyASN1(stdlibECPublicKey(t, keyPair.PublicKey), digest[:], sig) {
+		t.Fatal("ecdsa.VerifyASN1 rejected a signature from a generated key")
+	}
+}
+
+// TestECDSAOpenSSLInterop checks signatures in both directions with the
+// openssl command line, which uses the same DER encoding
+func TestECDSAOpenSSLInterop(t *testing.T) {
+	openssl, err := exec.LookPath("openssl")
+	if err != nil {
+		t.Skip("openssl not found")
+	}
+	dir := t.TempDir()
+	payloadPath := filepath.Join(dir, "payload")
+	sigPath := filepath.Join(dir, "sig")
+	payload := pathwell.CanonicalPayload("POST", "/v1/chat", interopBody, interopTimestamp, interopNonce)
+	if err := os.WriteFile(payloadPath, []byte(payload), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	keyPath := filepath.Join("testdata", "ec_p256_sec1.pem")
+	publicPath := filepath.Join("testdata", "ec_p256_public.pem")
+
+	// SDK signs, openssl verifies
+	signature, err := pathwell.SignRequest(readFixture(t, "ec_p256_sec1.pem"), "POST", "/v1/chat", interopBody, interopTimestamp, interopNonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, _ := base64.StdEncoding.DecodeString(signature)
+	if err := os.WriteFile(sigPath, sig, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	out, err := exec.Command(openssl, "dgst", "-sha256", "-verify", publicPath, "-signature", sigPath, payloadPath).CombinedOutput()
+	if err != nil || !strings.Contains(string(out), "Verified OK") {
+		t.Fatalf("openssl rejected the SDK signature: %v: %s", err, out)
+	}
+
+	// openssl signs, SDK verifies
+	out, err = exec.Command(openssl, "dgst", "-sha256", "-sign", keyPath, "-out", sigPath, payloadPath).CombinedOutput()
+	if err != nil {
+		t.Fatalf("openssl dgst -sign: %v: %s", err, out)
+	}
+	sig, err = os.ReadFile(sigPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = pathwell.VerifySignature(readFixture(t, "ec_p256_public.pem"), "POST", "/v1/chat", interopBody,
+		interopTimestamp, interopNonce, base64.StdEncoding.EncodeToString(sig))
+	if err != nil {
+		t.Fatalf("VerifySignature rejected an openssl signature: %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+package pathwell_test
+
+import (
+	"crypto"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pathwell/connect-go/pathwell"
+	"github.com/pathwell/connect-go/pathwell/pathwelltest"
+)
+
+// fixturePassphrase protects the encrypted keys in testdata, which were
+// written by openssl:
+//
+//	openssl pkcs8 -topk8 -v2 aes-256-cbc -in rsa_pkcs1.pem
+//	openssl pkcs8 -topk8 -v2 aes-128-cbc -v2prf hmacWithSHA1 -in rsa_pkcs1.pem
+//	openssl rsa -aes256 -traditional -in rsa_pkcs1.pem
+//	openssl ec -aes256 -in ec_p256_sec1.pem
+const fixturePassphrase = "correct-horse"
+
+var encryptedFixtures = []struct {
+	encrypted, plain string
+}{
+	{"rsa_pkcs8_aes256.pem", "rsa_pkcs1.pem"},
+	{"rsa_pkcs8_aes128_sha1.pem", "rsa_pkcs1.pem"},
+	{"ec_p256_pkcs8_aes256.pem", "ec_p256_sec1.pem"},
+	{"ed25519_pkcs8_aes256.pem", "ed25519_pkcs8.pem"},
+	{"rsa_legacy_aes256.pem", "rsa_pkcs1.pem"},
+	{"ec_p256_legacy_aes256.pem", "ec_p256_sec1.pem"},
+}
+
+func TestParsePrivateKeyWithPassphrase(t *testing.T) {
+	for _, tt := range encryptedFixtures {
+		signer, err := pathwell.ParsePrivateKeyWithPassphrase(readFixture(t, tt.encrypted), fixturePassphrase)
+		if err != nil {
+			t.Errorf("%s: %v", tt.encrypted, err)
+			continue
+		}
+		plain, err := pathwell.ParsePrivateKey(readFixture(t, tt.plain))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !signer.Public().(interface{ Equal(crypto.PublicKey) bool }).Equal(plain.Public()) {
+			t.Errorf("%s decrypted to a different key than %s", tt.encrypted, tt.plain)
+		}
+	}
+}
+
+func TestParsePrivateKeyWithWrongPassphrase(t *testing.T) {
+	// Legacy keys have no integrity check, so only PKCS#8 is guaranteed to
+	// report a wrong passphrase
+	for _, name := range []string{"rsa_pkcs8_aes256.pem", "rsa_pkcs8_aes128_sha1.pem", "ec_p256_pkcs8_aes256.pem", "ed25519_pkcs8_aes256.pem"} {
+		if _, err := pathwell.ParsePrivateKeyWithPassphrase(readFixture(t, name), "wrong"); err == nil {
+			t.Errorf("%s: decrypted with a wrong passphrase", name)
+		}
+	}
+}
+
+func TestParsePrivateKeyRejectsEncrypted(t *testing.T) {
+	for _, tt := range encryptedFixtures {
+		_, err := pathwell.ParsePrivateKey(readFixture(t, tt.encrypted))
+		if err == nil || !strings.Contains(err.Error(), "ParsePrivateKeyWithPassphrase") {
+			t.Errorf("%s: error = %v, want a pointer to ParsePrivateKeyWithPassphrase", tt.encrypted, err)
+		}
+	}
+}
+
+// NewClient decrypts PrivateKeyPath with Passphrase and signs verifiably
+func TestClientPassphrase(t *testing.T) {
+	proxy := pathwelltest.NewTestProxy(readFixture(t, "rsa_public.pem"), pathwelltest.ProxyOptions{})
+	defer proxy.Close()
+
+	for _, name := range []string{"rsa_pkcs8_aes256.pem", "rsa_legacy_aes256.pem"} {
+		client, err := pathwell.NewClient(pathwell.ClientOptions{
+			AgentID:        "agent-123",
+			PrivateKeyPath: filepath.Join("testdata", name),
+			Passphrase:     fixturePassphrase,
+			ProxyURL:       proxy.URL,
+			TargetURL:      "https://api.example.com",
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		resp, err := client.Get("/x", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("%s: status %d", name, resp.StatusCode)
+		}
+	}
+
+	_, err := pathwell.NewClient(pathwell.ClientOptions{
+		AgentID:        "agent-123",
+		PrivateKeyPath: filepath.Join("testdata", "rsa_pkcs8_aes256.pem"),
+		ProxyURL:       proxy.URL,
+	})
+	if err == nil {
+		t.Error("NewClient loaded an encrypted key without a passphrase")
+	}
+}
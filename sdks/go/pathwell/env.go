@@ -0,0 +1,31 @@
+package pathwell
+
+import "os"
+
+// Environment variables consulted for options left empty
+const (
+	ProxyURLEnv       = "PATHWELL_PROXY_URL"
+	AgentIDEnv        = "PATHWELL_AGENT_ID"
+	PrivateKeyPathEnv = "PATHWELL_PRIVATE_KEY_PATH"
+)
+
+// defaultProxyURL is the proxy used when neither ProxyURL nor
+// PATHWELL_PROXY_URL is set
+const defaultProxyURL = "http://localhost:8080"
+
+// applyEnvDefaults fills ProxyURL, AgentID and PrivateKeyPath from the
+// environment where options leaves them empty. An explicit option always
+// wins, and the key path is only taken when no Signer or PrivateKeyPEM
+// supplies the key some other way.
+func applyEnvDefaults(options ClientOptions) ClientOptions {
+	if options.ProxyURL == "" {
+		options.ProxyURL = os.Getenv(ProxyURLEnv)
+	}
+	if options.AgentID == "" {
+		options.AgentID = os.Getenv(AgentIDEnv)
+	}
+	if options.PrivateKeyPath == "" && options.PrivateKeyPEM == "" && options.Signer == nil {
+		options.PrivateKeyPath = os.Getenv(PrivateKeyPathEnv)
+	}
+	return options
+}
@@ -0,0 +1,109 @@
+package pathwell_test
+
+import (
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pathwell/connect-go/pathwell"
+)
+
+// buildRequest builds a signed request without sending it, so the proxy
+// URL and agent ID a client resolved can be inspected
+func buildRequest(t *testing.T, options pathwell.ClientOptions) *http.Request {
+	t.Helper()
+	client, err := pathwell.NewClient(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := client.BuildSignedRequest("GET", "https://api.example.com/x", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
+
+func TestEnvProxyURL(t *testing.T) {
+	keyPath := filepath.Join("testdata", "ed25519_pkcs8.pem")
+
+	t.Setenv(pathwell.ProxyURLEnv, "")
+	t.Setenv(pathwell.AgentIDEnv, "env-agent")
+	t.Setenv(pathwell.PrivateKeyPathEnv, keyPath)
+	if got := buildRequest(t, pathwell.ClientOptions{}).URL.Host; got != "localhost:8080" {
+		t.Errorf("built-in default: host %q", got)
+	}
+
+	t.Setenv(pathwell.ProxyURLEnv, "http://env-proxy:9000")
+	if got := buildRequest(t, pathwell.ClientOptions{}).URL.Host; got != "env-proxy:9000" {
+		t.Errorf("env: host %q", got)
+	}
+	if got := buildRequest(t, pathwell.ClientOptions{ProxyURL: "http://option-proxy"}).URL.Host; got != "option-proxy" {
+		t.Errorf("option over env: host %q", got)
+	}
+
+	t.Setenv(pathwell.ProxyURLEnv, "not a url")
+	if _, err := pathwell.NewClient(pathwell.ClientOptions{}); err == nil || !strings.Contains(err.Error(), "proxy URL") {
+		t.Errorf("invalid env proxy URL: error = %v", err)
+	}
+}
+
+func TestEnvAgentID(t *testing.T) {
+	t.Setenv(pathwell.ProxyURLEnv, "")
+	t.Setenv(pathwell.PrivateKeyPathEnv, filepath.Join("testdata", "ed25519_pkcs8.pem"))
+
+	t.Setenv(pathwell.AgentIDEnv, "")
+	if _, err := pathwell.NewClient(pathwell.ClientOptions{}); err == nil {
+		t.Error("NewClient succeeded without an agent ID")
+	}
+
+	t.Setenv(pathwell.AgentIDEnv, " env-agent ")
+	if got := buildRequest(t, pathwell.ClientOptions{}).Header.Get(pathwell.DefaultAgentIDHeader); got != "env-agent" {
+		t.Errorf("env: agent ID %q", got)
+	}
+	if got := buildRequest(t, pathwell.ClientOptions{AgentID: "option-agent"}).Header.Get(pathwell.DefaultAgentIDHeader); got != "option-agent" {
+		t.Errorf("option over env: agent ID %q", got)
+	}
+
+	// Environment values are validated like options
+	t.Setenv(pathwell.AgentIDEnv, "env-agent\r\nX-Evil: 1")
+	if _, err := pathwell.NewClient(pathwell.ClientOptions{}); err == nil {
+		t.Error("env agent ID with a line break accepted")
+	}
+}
+
+func TestEnvPrivateKeyPath(t *testing.T) {
+	t.Setenv(pathwell.ProxyURLEnv, "")
+	t.Setenv(pathwell.AgentIDEnv, "env-agent")
+	keyPEM := readFixture(t, "ed25519_pkcs8.pem")
+
+	t.Setenv(pathwell.PrivateKeyPathEnv, "")
+	if _, err := pathwell.NewClient(pathwell.ClientOptions{}); err == nil {
+		t.Error("NewClient succeeded without a key")
+	}
+
+	t.Setenv(pathwell.PrivateKeyPathEnv, filepath.Join("testdata", "ed25519_pkcs8.pem"))
+	if _, err := pathwell.NewClient(pathwell.ClientOptions{}); err != nil {
+		t.Errorf("env key path: %v", err)
+	}
+
+	// The env path is only a fallback: an explicit key of any kind wins,
+	// so a missing file there is never read
+	t.Setenv(pathwell.PrivateKeyPathEnv, filepath.Join("testdata", "missing.pem"))
+	if _, err := pathwell.NewClient(pathwell.ClientOptions{}); err == nil {
+		t.Error("missing env key file accepted")
+	}
+	if _, err := pathwell.NewClient(pathwell.ClientOptions{PrivateKeyPEM: keyPEM}); err != nil {
+		t.Errorf("PrivateKeyPEM over env: %v", err)
+	}
+	signer, err := pathwell.ParsePrivateKey(keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pathwell.NewClient(pathwell.ClientOptions{Signer: signer}); err != nil {
+		t.Errorf("Signer over env: %v", err)
+	}
+	if _, err := pathwell.NewClient(pathwell.ClientOptions{PrivateKeyPath: filepath.Join("testdata", "rsa_pkcs1.pem")}); err != nil {
+		t.Errorf("PrivateKeyPath over env: %v", err)
+	}
+}
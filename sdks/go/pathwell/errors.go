@@ -0,0 +1,203 @@
+package pathwell
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+)
+
+// Errors wrapped around the cause of a failed call, so callers can tell
+// where it failed with errors.Is
+var (
+	// ErrInvalidURL means the request URL could not be parsed
+	ErrInvalidURL = errors.New("invalid URL")
+	// ErrSigning means the request could not be signed, for example because
+	// the key was refused or is outside its validity window
+	ErrSigning = errors.New("failed to sign request")
+	// ErrTransport means the request was signed but sending it failed
+	// before a response arrived: DNS, connection, TLS or timeout errors
+	ErrTransport = errors.New("request failed")
+
+	// Transport failures are further classified by wrapping one of these
+	// alongside ErrTransport, when the cause is recognized
+
+	// ErrProxyUnreachable means no connection to the proxy could be opened,
+	// e.g. it was refused or the network is unreachable. Ping wraps it
+	// around every transport failure.
+	ErrProxyUnreachable = errors.New("proxy unreachable")
+	// ErrEgressProxy means the connection could not be opened through the
+	// EgressProxyURL (or environment) network proxy, which is distinct from
+	// the Pathwell proxy being unreachable
+	ErrEgressProxy = errors.New("egress proxy connection failed")
+	// ErrDNS means the proxy host name could not be resolved
+	ErrDNS = errors.New("DNS lookup failed")
+	// ErrTLSHandshake means the TLS handshake with the proxy failed, e.g.
+	// an untrusted certificate or a rejected client certificate
+	ErrTLSHandshake = errors.New("TLS handshake failed")
+	// ErrTimeout means connecting or waiting for the response timed out
+	ErrTimeout = errors.New("request timed out")
+	// ErrResponseTooLarge means a response body was longer than
+	// MaxResponseBytes and was not read in full
+	ErrResponseTooLarge = errors.New("response body too large")
+)
+
+// maxErrorBodySize bounds how much of a failed response body is kept on an APIError
+const maxErrorBodySize = 64 << 10
+
+// APIError is returned for responses with a non-2xx status code. When the
+// body is a JSON error envelope such as {"error": "...", "code": "..."},
+// Code and Message hold its fields; Body always holds the raw bytes.
+type APIError struct {
+	StatusCode int
+	Status     string
+	Body       []byte
+	RequestID  string
+	Code       string
+	Message    string
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("pathwell: request failed with status %s", e.Status)
+	if e.RequestID != "" {
+		msg += fmt.Sprintf(" (request id %s)", e.RequestID)
+	}
+	switch {
+	case e.Message != "" && e.Code != "":
+		msg += fmt.Sprintf(": %s: %s", e.Code, e.Message)
+	case e.Message != "":
+		msg += fmt.Sprintf(": %s", e.Message)
+	case len(e.Body) > 0:
+		msg += fmt.Sprintf(": %s", e.Body)
+	}
+	return msg
+}
+
+// errorEnvelope is the JSON error body returned by the proxy. Message is
+// accepted as an alias of Error.
+type errorEnvelope struct {
+	Error   string `json:"error"`
+	Message string `json:"message"`
+	Code    string `json:"code"`
+}
+
+// parseErrorEnvelope extracts the code and message of a JSON error body. It
+// reports false for bodies that are not an error envelope, including JSON
+// whose fields have other types.
+func parseErrorEnvelope(body []byte) (code, message string, ok bool) {
+	var envelope errorEnvelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return "", "", false
+	}
+	message = envelope.Error
+	if message == "" {
+		message = envelope.Message
+	}
+	if message == "" && envelope.Code == "" {
+		return "", "", false
+	}
+	return envelope.Code, message, true
+}
+
+// transportError wraps a failure of http.Client.Do in ErrTransport and,
+// when the cause is recognized, in the sentinel that classifies it
+func transportError(err error) error {
+	if class := classifyTransportError(err); class != nil {
+		return fmt.Errorf("%w: %w: %w", ErrTransport, class, err)
+	}
+	return fmt.Errorf("%w: %w", ErrTransport, err)
+}
+
+// classifyTransportError returns ErrDNS, ErrTLSHandshake, ErrTimeout,
+// ErrEgressProxy or ErrProxyUnreachable for err, or nil when it is none of
+// them, such as a cancelled context or a connection reset mid-response
+func classifyTransportError(err error) error {
+	// net/http reports failures reaching or negotiating with a network
+	// proxy as "proxyconnect" (HTTP) or "socks connect" operations; these
+	// take precedence over the DNS, TLS or timeout error underneath
+	var opErr *net.OpError
+	hasOpErr := errors.As(err, &opErr)
+	if hasOpErr && (opErr.Op == "proxyconnect" || opErr.Op == "socks connect") {
+		return ErrEgressProxy
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return ErrDNS
+	}
+
+	var (
+		recordErr    tls.RecordHeaderError
+		verifyErr    *tls.CertificateVerificationError
+		authorityErr x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		invalidErr   x509.CertificateInvalidError
+	)
+	if errors.As(err, &recordErr) || errors.As(err, &verifyErr) || errors.As(err, &authorityErr) ||
+		errors.As(err, &hostnameErr) || errors.As(err, &invalidErr) {
+		return ErrTLSHandshake
+	}
+
+	// crypto/tls reports alerts sent by the peer, such as a rejected
+	// client certificate, as "remote error" operations
+	if hasOpErr && opErr.Op == "remote error" {
+		return ErrTLSHandshake
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return ErrTimeout
+	}
+
+	if hasOpErr && opErr.Op == "dial" {
+		return ErrProxyUnreachable
+	}
+	return nil
+}
+
+// readLimited reads r to the end, failing with ErrResponseTooLarge once more
+// than limit bytes arrive. A limit of zero or less reads without bound.
+func readLimited(r io.Reader, limit int64) ([]byte, error) {
+	if limit <= 0 {
+		return io.ReadAll(r)
+	}
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrResponseTooLarge, limit)
+	}
+	return data, nil
+}
+
+// CheckStatus returns nil for 2xx responses. For any other status it keeps
+// up to 64 KiB of the body, drains the rest up to 256 KiB so the connection
+// can be reused, closes it and returns an *APIError, with Code and Message
+// filled in when the body is a JSON error envelope.
+func CheckStatus(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	defer discardBody(resp)
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Body:       body,
+		RequestID:  RequestID(resp),
+	}
+	if code, message, ok := parseErrorEnvelope(body); ok {
+		apiErr.Code = code
+		apiErr.Message = message
+	}
+	return apiErr
+}
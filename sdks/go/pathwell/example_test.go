@@ -0,0 +1,46 @@
+package pathwell_test
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/pathwell/connect-go/pathwell"
+	"github.com/pathwell/connect-go/pathwell/pathwelltest"
+)
+
+// A Client is safe for concurrent use: share one between goroutines rather
+// than building one per request. The test suite runs this with -race.
+func ExampleClient_concurrent() {
+	keyPair, _ := pathwell.GenerateKeyPairWithAlgorithm(pathwell.Ed25519)
+	proxy := pathwelltest.NewTestProxy(keyPair.PublicKey, pathwelltest.ProxyOptions{})
+	defer proxy.Close()
+
+	client, err := pathwell.NewClient(pathwell.ClientOptions{
+		AgentID:       "agent-123",
+		PrivateKeyPEM: keyPair.PrivateKey,
+		ProxyURL:      proxy.URL,
+		BaseURL:       "https://api.example.com/v1/",
+	})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	var wg sync.WaitGroup
+	for id := 0; id < 10; id++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			resp, err := client.Get(fmt.Sprintf("items/%d", id), nil)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			resp.Body.Close()
+		}(id)
+	}
+	wg.Wait()
+
+	fmt.Println(len(proxy.Requests()), "signed requests verified")
+	// Output: 10 signed requests verified
+}
@@ -0,0 +1,135 @@
+package pathwell
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// JWK is a public key in JSON Web Key format (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+
+	// RSA keys
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// Ed25519 and EC keys; Y is set for EC keys only
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// PublicKeyToOpenSSH converts a PEM public key to a single OpenSSH
+// authorized_keys line, such as "ssh-ed25519 AAAA..."
+func PublicKeyToOpenSSH(publicKeyPEM string) (string, error) {
+	publicKey, err := ParsePublicKey(publicKeyPEM)
+	if err != nil {
+		return "", err
+	}
+
+	sshKey, err := ssh.NewPublicKey(publicKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to convert public key: %w", err)
+	}
+
+	return strings.TrimSuffix(string(ssh.MarshalAuthorizedKey(sshKey)), "\n"), nil
+}
+
+// PublicKeyToJWK converts a PEM public key to a JWK. RSA keys carry n and e,
+// Ed25519 keys carry crv and x, and P-256 keys carry crv, x and y. The kid is
+// the RFC 7638 SHA-256 thumbprint.
+func PublicKeyToJWK(publicKeyPEM string) (*JWK, error) {
+	publicKey, err := ParsePublicKey(publicKeyPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	var jwk JWK
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		jwk = JWK{
+			Kty: "RSA",
+			Alg: "RS256",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}
+	case ed25519.PublicKey:
+		jwk = JWK{
+			Kty: "OKP",
+			Alg: "EdDSA",
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(key),
+		}
+	case *ecdsa.PublicKey:
+		if err := checkPublicKeyType(key); err != nil {
+			return nil, err
+		}
+		// Coordinates are fixed-size, left-padded to the curve's byte length
+		size := (key.Curve.Params().BitSize + 7) / 8
+		jwk = JWK{
+			Kty: "EC",
+			Alg: "ES256",
+			Crv: "P-256",
+			X:   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, size))),
+			Y:   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, size))),
+		}
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+	jwk.Use = "sig"
+
+	kid, err := jwkThumbprint(&jwk)
+	if err != nil {
+		return nil, err
+	}
+	jwk.Kid = kid
+
+	return &jwk, nil
+}
+
+// jwkThumbprint computes the RFC 7638 thumbprint: the SHA-256 of the
+// required members, in lexicographic order, without whitespace
+func jwkThumbprint(jwk *JWK) (string, error) {
+	var members interface{}
+	switch jwk.Kty {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.Kty, jwk.N}
+	case "EC":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		}{jwk.Crv, jwk.Kty, jwk.X, jwk.Y}
+	default:
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Crv, jwk.Kty, jwk.X}
+	}
+
+	data, err := json.Marshal(members)
+	if err != nil {
+		return "", fmt.Errorf("failed to compute JWK thumbprint: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
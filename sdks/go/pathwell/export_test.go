@@ -0,0 +1,160 @@
+package pathwell_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/pathwell/connect-go/pathwell"
+)
+
+// Key vectors from RFC 7638 section 3.1 (RSA), RFC 8037 appendix A.3
+// (Ed25519) and RFC 7517 appendix A.1 (P-256)
+const (
+	rfc7638N = "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw"
+	rfc8037X = "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"
+	rfc7517X = "MKBCTNIcKUSDii11ySs3526iDZ8AiTo7Tu6KPAqv7D4"
+	rfc7517Y = "4Etl6SRW2YiLUrN5vfvVHuhp7x8PxltmWWlbbM4IFyM"
+)
+
+func TestPublicKeyToJWK(t *testing.T) {
+	// RFC 7517 gives no thumbprint for its EC key; hash the RFC 7638 form
+	// of its members directly
+	ecMembers := sha256.Sum256([]byte(`{"crv":"P-256","kty":"EC","x":"` + rfc7517X + `","y":"` + rfc7517Y + `"}`))
+
+	tests := []struct {
+		name string
+		key  crypto.PublicKey
+		want pathwell.JWK
+	}{
+		{
+			name: "RSA",
+			key:  &rsa.PublicKey{N: decodeBigInt(t, rfc7638N), E: 65537},
+			want: pathwell.JWK{
+				Kty: "RSA", Kid: "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs", Use: "sig", Alg: "RS256",
+				N: rfc7638N, E: "AQAB",
+			},
+		},
+		{
+			name: "Ed25519",
+			key:  ed25519.PublicKey(decodeBase64URL(t, rfc8037X)),
+			want: pathwell.JWK{
+				Kty: "OKP", Kid: "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k", Use: "sig", Alg: "EdDSA",
+				Crv: "Ed25519", X: rfc8037X,
+			},
+		},
+		{
+			name: "P-256",
+			key: &ecdsa.PublicKey{
+				Curve: elliptic.P256(),
+				X:     decodeBigInt(t, rfc7517X),
+				Y:     decodeBigInt(t, rfc7517Y),
+			},
+			want: pathwell.JWK{
+				Kty: "EC", Kid: base64.RawURLEncoding.EncodeToString(ecMembers[:]), Use: "sig", Alg: "ES256",
+				Crv: "P-256", X: rfc7517X, Y: rfc7517Y,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jwk, err := pathwell.PublicKeyToJWK(encodePublicKeyPEM(t, tt.key))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *jwk != tt.want {
+				t.Errorf("PublicKeyToJWK = %+v, want %+v", *jwk, tt.want)
+			}
+		})
+	}
+}
+
+func TestPublicKeyToOpenSSH(t *testing.T) {
+	tests := []struct {
+		fixture  string
+		wantType string
+	}{
+		{"rsa_public.pem", ssh.KeyAlgoRSA},
+		{"ed25519_public.pem", ssh.KeyAlgoED25519},
+		{"ec_p256_public.pem", ssh.KeyAlgoECDSA256},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			publicKeyPEM := readFixture(t, tt.fixture)
+			line, err := pathwell.PublicKeyToOpenSSH(publicKeyPEM)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(line, "\n") {
+				t.Errorf("authorized_keys line has a newline: %q", line)
+			}
+
+			parsed, _, _, rest, err := ssh.ParseAuthorizedKey([]byte(line))
+			if err != nil {
+				t.Fatalf("ParseAuthorizedKey(%q): %v", line, err)
+			}
+			if len(rest) != 0 {
+				t.Errorf("trailing data after key: %q", rest)
+			}
+			if parsed.Type() != tt.wantType {
+				t.Errorf("key type = %q, want %q", parsed.Type(), tt.wantType)
+			}
+
+			want, err := pathwell.ParsePublicKey(publicKeyPEM)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := parsed.(ssh.CryptoPublicKey).CryptoPublicKey()
+			if !want.(interface{ Equal(crypto.PublicKey) bool }).Equal(got) {
+				t.Error("parsed OpenSSH key differs from the PEM key")
+			}
+		})
+	}
+}
+
+func TestPublicKeyToOpenSSHEd25519Vector(t *testing.T) {
+	line, err := pathwell.PublicKeyToOpenSSH(encodePublicKeyPEM(t, ed25519.PublicKey(decodeBase64URL(t, rfc8037X))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The wire format is the string "ssh-ed25519" and the 32-byte key, each
+	// length-prefixed
+	wire := append([]byte("\x00\x00\x00\x0bssh-ed25519\x00\x00\x00\x20"), decodeBase64URL(t, rfc8037X)...)
+	if want := "ssh-ed25519 " + base64.StdEncoding.EncodeToString(wire); line != want {
+		t.Errorf("PublicKeyToOpenSSH = %q, want %q", line, want)
+	}
+}
+
+func encodePublicKeyPEM(t *testing.T, key crypto.PublicKey) string {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func decodeBase64URL(t *testing.T, s string) []byte {
+	t.Helper()
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func decodeBigInt(t *testing.T, s string) *big.Int {
+	t.Helper()
+	return new(big.Int).SetBytes(decodeBase64URL(t, s))
+}
@@ -0,0 +1,77 @@
+package pathwell
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Default names of the headers the client signs requests with
+const (
+	DefaultAgentIDHeader   = "X-Pathwell-Agent-ID"
+	DefaultSignatureHeader = "X-Pathwell-Signature"
+	DefaultTimestampHeader = "X-Pathwell-Timestamp"
+	DefaultNonceHeader     = "X-Pathwell-Nonce"
+	DefaultKeyIDHeader     = "X-Pathwell-Key-ID"
+	DefaultTargetHeader    = "X-Pathwell-Target"
+
+	// DefaultSignedHeadersHeader lists, lowercase and separated by ";", the
+	// request headers folded into the signature, so the proxy knows which
+	// to verify
+	DefaultSignedHeadersHeader = "X-Pathwell-Signed-Headers"
+	// DefaultSignedHostHeader carries the canonical upstream host covered
+	// by the signature when IncludeHostInSignature is set
+	DefaultSignedHostHeader = "X-Pathwell-Signed-Host"
+)
+
+// headerNames holds the resolved names of the signing headers
+type headerNames struct {
+	agentID   string
+	signature string
+	timestamp string
+	nonce     string
+	keyID     string
+	target    string
+
+	signedHeaders string
+	signedHost    string
+}
+
+// newHeaderNames applies the header name overrides in options
+func newHeaderNames(options ClientOptions) headerNames {
+	return headerNames{
+		agentID:   orDefault(options.AgentIDHeader, DefaultAgentIDHeader),
+		signature: orDefault(options.SignatureHeader, DefaultSignatureHeader),
+		timestamp: orDefault(options.TimestampHeader, DefaultTimestampHeader),
+		nonce:     orDefault(options.NonceHeader, DefaultNonceHeader),
+		keyID:     orDefault(options.KeyIDHeader, DefaultKeyIDHeader),
+		target:    orDefault(options.TargetHeader, DefaultTargetHeader),
+
+		signedHeaders: orDefault(options.SignedHeadersHeader, DefaultSignedHeadersHeader),
+		signedHost:    orDefault(options.SignedHostHeader, DefaultSignedHostHeader),
+	}
+}
+
+// isReserved reports whether name is a signing header or in the X-Pathwell-
+// namespace, and so may not be set through DefaultHeaders
+func (h headerNames) isReserved(name string) bool {
+	name = http.CanonicalHeaderKey(name)
+	if strings.HasPrefix(name, "X-Pathwell-") {
+		return true
+	}
+	for _, reserved := range []string{
+		h.agentID, h.signature, h.timestamp, h.nonce, h.keyID, h.target, h.signedHeaders, h.signedHost,
+	} {
+		if name == http.CanonicalHeaderKey(reserved) {
+			return true
+		}
+	}
+	return false
+}
+
+// orDefault returns value, or fallback when value is empty
+func orDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
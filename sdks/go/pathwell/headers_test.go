@@ -0,0 +1,163 @@
+package pathwell_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pathwell/connect-go/pathwell"
+	"github.com/pathwell/connect-go/pathwell/pathwelltest"
+)
+
+// edgeHeaderNames renames every header the proxy needs out of X-Pathwell-*
+var edgeHeaderNames = pathwell.ClientOptions{
+	AgentIDHeader:       "X-Edge-Agent",
+	SignatureHeader:     "X-Edge-Signature",
+	TimestampHeader:     "X-Edge-Timestamp",
+	NonceHeader:         "X-Edge-Nonce",
+	KeyIDHeader:         "X-Edge-Key-ID",
+	TargetHeader:        "X-Edge-Target",
+	SignedHeadersHeader: "X-Edge-Signed-Headers",
+	SignedHostHeader:    "X-Edge-Signed-Host",
+}
+
+func TestSignedHeaderNamesFollowOverrides(t *testing.T) {
+	keyPair, err := pathwell.GenerateKeyPairWithAlgorithm(pathwell.Ed25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var verifyErr error
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		body, _ := io.ReadAll(r.Body)
+		var signed map[string]string
+		if names := r.Header.Get("X-Edge-Signed-Headers"); names != "" {
+			signed = make(map[string]string)
+			for _, name := range strings.Split(names, ";") {
+				signed[name] = r.Header.Get(name)
+			}
+		}
+		verifyErr = pathwell.SignatureInput{
+			Method:    r.Method,
+			Path:      r.URL.RequestURI(),
+			Body:      body,
+			Timestamp: r.Header.Get("X-Edge-Timestamp"),
+			Nonce:     r.Header.Get("X-Edge-Nonce"),
+			KeyID:     r.Header.Get("X-Edge-Key-ID"),
+			Host:      r.Header.Get("X-Edge-Signed-Host"),
+			Headers:   signed,
+		}.Verify(keyPair.PublicKey, r.Header.Get("X-Edge-Signature"))
+	}))
+	defer server.Close()
+
+	options := edgeHeaderNames
+	options.AgentID = "agent-123"
+	options.PrivateKeyPEM = keyPair.PrivateKey
+	options.ProxyURL = server.URL
+	options.KeyID = "key-1"
+	options.SignedHeaders = []string{"Content-Type"}
+	options.IncludeHostInSignature = true
+	client, err := pathwell.NewClient(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := client.Post("https://API.example.com:443/v1/items",
+		map[string]string{"Content-Type": "application/json"}, map[string]int{"n": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if verifyErr != nil {
+		t.Fatalf("signature did not verify under the custom names: %v", verifyErr)
+	}
+	if got := header.Get("X-Edge-Signed-Headers"); got != "content-type" {
+		t.Errorf("X-Edge-Signed-Headers = %q", got)
+	}
+	if got := header.Get("X-Edge-Signed-Host"); got != "https://api.example.com" {
+		t.Errorf("X-Edge-Signed-Host = %q", got)
+	}
+	for name := range header {
+		if strings.HasPrefix(name, "X-Pathwell-") {
+			t.Errorf("%s was sent despite the overrides", name)
+		}
+	}
+}
+
+func TestSignedHeadersRejectsSigningHeaderNames(t *testing.T) {
+	keyPair, err := pathwell.GenerateKeyPairWithAlgorithm(pathwell.Ed25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"X-Pathwell-Nonce", "x-edge-signed-host", "X-Edge-Signature"} {
+		options := edgeHeaderNames
+		options.AgentID = "agent-123"
+		options.PrivateKeyPEM = keyPair.PrivateKey
+		options.SignedHeaders = []string{name}
+		if _, err := pathwell.NewClient(options); err == nil {
+			t.Errorf("SignedHeaders %q: expected an error", name)
+		}
+	}
+}
+
+// Custom names replace the defaults on the wire and get the same protection:
+// DefaultHeaders and per-call headers cannot override them
+func TestCustomHeaderNames(t *testing.T) {
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+	}))
+	defer server.Close()
+
+	options := edgeHeaderNames
+	options.KeyID = "key-1"
+	options.DefaultHeaders = map[string]string{"X-Edge-Agent": "spoofed", "x-edge-nonce": "fixed", "X-Team": "core"}
+	client := newStubClient(t, server.URL, options)
+
+	resp, err := client.Get("https://api.example.com/x", map[string]string{"X-Edge-Target": "https://evil.example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	want := map[string]string{
+		"X-Edge-Agent":  "agent-123",
+		"X-Edge-Target": "https://api.example.com",
+		"X-Edge-Key-Id": "key-1",
+		"X-Team":        "core",
+	}
+	for name, value := range want {
+		if got := header.Values(name); len(got) != 1 || got[0] != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+	for _, name := range []string{"X-Edge-Signature", "X-Edge-Timestamp", "X-Edge-Nonce"} {
+		if header.Get(name) == "" || header.Get(name) == "fixed" {
+			t.Errorf("%s = %q", name, header.Get(name))
+		}
+	}
+	for name := range header {
+		if strings.HasPrefix(name, "X-Pathwell-") {
+			t.Errorf("%s was sent despite the overrides", name)
+		}
+	}
+}
+
+// A proxy expecting the default names cannot verify a client using others
+func TestCustomHeaderNamesMismatch(t *testing.T) {
+	options := edgeHeaderNames
+	options.TargetURL = "https://api.example.com"
+	_, client := newProxyClient(t, pathwelltest.ProxyOptions{}, options)
+
+	resp, err := client.Get("/x", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("status %d, want 401", resp.StatusCode)
+	}
+}
@@ -0,0 +1,57 @@
+package pathwell
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// IdempotencyKeyHeader carries the key that lets the proxy and upstream
+// drop duplicate deliveries of the same logical call
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// idempotencyKey returns the key for a call: the caller's Idempotency-Key
+// header if set, otherwise a generated key (a UUID by default) for POST
+// and PATCH when generate is non-nil, otherwise ""
+func idempotencyKey(method string, headers map[string]string, generate func() (string, error)) (string, error) {
+	for k, v := range headers {
+		if http.CanonicalHeaderKey(k) == IdempotencyKeyHeader {
+			if strings.ContainsAny(v, "\r\n") {
+				return "", fmt.Errorf("invalid idempotency key %q: must not contain line breaks", v)
+			}
+			return v, nil
+		}
+	}
+
+	if generate == nil || method != http.MethodPost && method != http.MethodPatch {
+		return "", nil
+	}
+	return generate()
+}
+
+// newUUID returns a random (version 4) UUID
+func newUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to generate idempotency key: %w", err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+// checkedIDGenerator wraps an IDGenerator so that the IDs it returns are
+// safe to sign: a line break would split a line of the canonical payload
+func checkedIDGenerator(generate func() (string, error)) func() (string, error) {
+	return func() (string, error) {
+		id, err := generate()
+		if err != nil {
+			return "", fmt.Errorf("failed to generate ID: %w", err)
+		}
+		if id == "" || strings.ContainsAny(id, "\r\n") {
+			return "", fmt.Errorf("invalid generated ID %q: must be non-empty without line breaks", id)
+		}
+		return id, nil
+	}
+}
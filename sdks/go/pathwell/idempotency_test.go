@@ -0,0 +1,68 @@
+package pathwell_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/pathwell/connect-go/pathwell"
+	"github.com/pathwell/connect-go/pathwell/pathwelltest"
+)
+
+func TestCallerIdempotencyKeyIsSigned(t *testing.T) {
+	for _, generate := range []bool{false, true} {
+		proxy, client := newProxyClient(t, pathwelltest.ProxyOptions{}, pathwell.ClientOptions{
+			TargetURL:       "https://api.example.com",
+			IdempotencyKeys: generate,
+		})
+
+		resp, err := client.Post("/x", map[string]string{"Idempotency-Key": "abc"}, map[string]string{"a": "b"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("IdempotencyKeys=%v, header: status %d", generate, resp.StatusCode)
+		}
+
+		resp, err = client.Call("PUT", "/x", nil, "body", pathwell.WithIdempotencyKey("abc"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("IdempotencyKeys=%v, WithIdempotencyKey: status %d", generate, resp.StatusCode)
+		}
+
+		for _, req := range proxy.Requests() {
+			if got := req.Header.Get(pathwell.IdempotencyKeyHeader); got != "abc" {
+				t.Errorf("Idempotency-Key = %q, want abc", got)
+			}
+		}
+	}
+}
+
+func TestGeneratedIdempotencyKeyIsSigned(t *testing.T) {
+	proxy, client := newProxyClient(t, pathwelltest.ProxyOptions{}, pathwell.ClientOptions{
+		TargetURL:       "https://api.example.com",
+		IdempotencyKeys: true,
+	})
+
+	resp, err := client.Post("/x", nil, map[string]string{"a": "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status %d", resp.StatusCode)
+	}
+	if got := proxy.Requests()[0].Header.Get(pathwell.IdempotencyKeyHeader); got == "" {
+		t.Error("no Idempotency-Key was generated")
+	}
+}
+
+func TestIdempotencyKeyRejectsLineBreaks(t *testing.T) {
+	_, client := newProxyClient(t, pathwelltest.ProxyOptions{}, pathwell.ClientOptions{})
+	if _, err := client.Post("https://api.example.com/x", map[string]string{"Idempotency-Key": "a\r\nb"}, nil); err == nil {
+		t.Fatal("expected an error for a key with a line break")
+	}
+}
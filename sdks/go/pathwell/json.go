@@ -0,0 +1,136 @@
+package pathwell
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// CallJSON makes an authenticated request, checks the status and decodes the
+// JSON response into out. The response body is always closed. It sends
+// Accept: application/json unless headers or DefaultHeaders set Accept.
+func (c *Client) CallJSON(
+	method string,
+	requestURL string,
+	headers map[string]string,
+	body interface{},
+	out interface{},
+) error {
+	return c.CallJSONContext(context.Background(), method, requestURL, headers, body, out)
+}
+
+// CallJSONContext is CallJSON bound to ctx
+func (c *Client) CallJSONContext(
+	ctx context.Context,
+	method string,
+	requestURL string,
+	headers map[string]string,
+	body interface{},
+	out interface{},
+) error {
+	_, err := c.CallJSONWithHeadersContext(ctx, method, requestURL, headers, body, out)
+	return err
+}
+
+// CallJSONWithHeaders is CallJSON that also returns the response headers,
+// for values such as pagination cursors or rate-limit counters. The headers
+// are also returned with the *APIError of a non-2xx response, unless
+// ErrorOnHTTPError made Call fail before the response reached the helper.
+func (c *Client) CallJSONWithHeaders(
+	method string,
+	requestURL string,
+	headers map[string]string,
+	body interface{},
+	out interface{},
+) (http.Header, error) {
+	return c.CallJSONWithHeadersContext(context.Background(), method, requestURL, headers, body, out)
+}
+
+// CallJSONWithHeadersContext is CallJSONWithHeaders bound to ctx
+func (c *Client) CallJSONWithHeadersContext(
+	ctx context.Context,
+	method string,
+	requestURL string,
+	headers map[string]string,
+	body interface{},
+	out interface{},
+) (http.Header, error) {
+	reqHeaders := make(map[string]string, len(headers)+1)
+	for k, v := range headers {
+		reqHeaders[http.CanonicalHeaderKey(k)] = v
+	}
+	// An Accept from DefaultHeaders would otherwise be overridden here, as
+	// per-call headers take precedence
+	if !hasHeader(reqHeaders, "Accept") && !hasHeader(c.defaultHeaders, "Accept") {
+		reqHeaders["Accept"] = "application/json"
+	}
+
+	resp, err := c.CallContext(ctx, method, requestURL, reqHeaders, body)
+	if err != nil {
+		return nil, err
+	}
+	defer discardBody(resp)
+
+	if err := decompressResponse(resp); err != nil {
+		return resp.Header, err
+	}
+
+	if err := CheckStatus(resp); err != nil {
+		return resp.Header, err
+	}
+
+	return resp.Header, decodeJSON(resp, out, c.maxResponseBytes)
+}
+
+// Do makes an authenticated request and decodes the JSON response into a T
+func Do[T any](
+	ctx context.Context,
+	c *Client,
+	method string,
+	requestURL string,
+	headers map[string]string,
+	body interface{},
+) (T, error) {
+	var out T
+	err := c.CallJSONContext(ctx, method, requestURL, headers, body, &out)
+	return out, err
+}
+
+// decodeJSON decodes a JSON response body of at most limit bytes into out.
+// Empty bodies are accepted and leave out untouched.
+func decodeJSON(resp *http.Response, out interface{}, limit int64) error {
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+
+	data, err := readLimited(resp.Body, limit)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" && !isJSONContentType(contentType) {
+		return fmt.Errorf("expected JSON response, got Content-Type %q", contentType)
+	}
+
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode JSON response: %w", err)
+	}
+	return nil
+}
+
+// isJSONContentType reports whether contentType is application/json or a +json type
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
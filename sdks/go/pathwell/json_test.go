@@ -0,0 +1,33 @@
+package pathwell_test
+
+import (
+	"testing"
+
+	"github.com/pathwell/connect-go/pathwell"
+)
+
+func TestCallJSONAccept(t *testing.T) {
+	tests := []struct {
+		name           string
+		defaultHeaders map[string]string
+		headers        map[string]string
+		want           string
+	}{
+		{"default", nil, nil, "application/json"},
+		{"per-call", nil, map[string]string{"accept": "application/problem+json"}, "application/problem+json"},
+		{"DefaultHeaders", map[string]string{"Accept": "application/vnd.api+json"}, nil, "application/vnd.api+json"},
+		{"per-call over DefaultHeaders", map[string]string{"accept": "application/vnd.api+json"}, map[string]string{"Accept": "text/plain"}, "text/plain"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, last := captureServer(t)
+			client := newStubClient(t, server.URL, pathwell.ClientOptions{DefaultHeaders: tt.defaultHeaders})
+			if err := client.CallJSON("GET", "https://api.example.com/items", tt.headers, nil, nil); err != nil {
+				t.Fatal(err)
+			}
+			if got := last().Header.Values("Accept"); len(got) != 1 || got[0] != tt.want {
+				t.Errorf("Accept = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
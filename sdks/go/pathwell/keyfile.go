@@ -0,0 +1,84 @@
+package pathwell
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// WriteKeyPair writes the private key to privatePath with 0600 permissions
+// and the public key to publicPath with 0644. Each file is written to a
+// temporary file in the same directory and renamed into place, so a crash
+// never leaves a partial key behind. Existing files are only replaced when
+// overwrite is true; without it, a failure to write the public key removes
+// the private key again.
+func WriteKeyPair(kp *KeyPair, privatePath, publicPath string, overwrite bool) error {
+	if kp == nil {
+		return fmt.Errorf("key pair is nil")
+	}
+
+	if !overwrite {
+		for _, path := range []string{privatePath, publicPath} {
+			if _, err := os.Lstat(path); err == nil {
+				return fmt.Errorf("refusing to overwrite existing key file %s", path)
+			} else if !errors.Is(err, fs.ErrNotExist) {
+				return fmt.Errorf("failed to check key file %s: %w", path, err)
+			}
+		}
+	}
+
+	if err := writeFileAtomic(privatePath, []byte(kp.PrivateKey), 0o600, overwrite); err != nil {
+		return fmt.Errorf("failed to write private key: %w", err)
+	}
+	if err := writeFileAtomic(publicPath, []byte(kp.PublicKey), 0o644, overwrite); err != nil {
+		if !overwrite {
+			// The private key is ours, linked in above; don't leave it
+			// without its public key, such as when another process created
+			// publicPath since the check
+			os.Remove(privatePath)
+		}
+		return fmt.Errorf("failed to write public key: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes data to a temporary file next to path with the given
+// permissions, syncs it and renames it over path. Without replace it is
+// hard-linked into place instead, which fails with an error wrapping
+// fs.ErrExist if path exists, even when another process created it
+// concurrently.
+func writeFileAtomic(path string, data []byte, perm os.FileMode, replace bool) (err error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+		}
+		// After a link the temporary name is a second name for the file;
+		// after a rename it is already gone
+		os.Remove(tmp.Name())
+	}()
+
+	// CreateTemp already uses 0600; Chmod also sets the public key's 0644
+	// regardless of the umask
+	if err = tmp.Chmod(perm); err != nil {
+		return err
+	}
+	if _, err = tmp.Write(data); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if !replace {
+		return os.Link(tmp.Name(), path)
+	}
+	return os.Rename(tmp.Name(), path)
+}
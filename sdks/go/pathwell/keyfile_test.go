@@ -0,0 +1,86 @@
+package pathwell_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pathwell/connect-go/pathwell"
+)
+
+func TestWriteKeyPair(t *testing.T) {
+	dir := t.TempDir()
+	privatePath := filepath.Join(dir, "agent.key")
+	publicPath := filepath.Join(dir, "agent.pub")
+
+	first, err := pathwell.GenerateKeyPairWithAlgorithm(pathwell.Ed25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pathwell.WriteKeyPair(first, privatePath, publicPath, false); err != nil {
+		t.Fatal(err)
+	}
+	assertKeyFile(t, privatePath, first.PrivateKey, 0o600)
+	assertKeyFile(t, publicPath, first.PublicKey, 0o644)
+
+	second, err := pathwell.GenerateKeyPairWithAlgorithm(pathwell.Ed25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pathwell.WriteKeyPair(second, privatePath, publicPath, false); err == nil {
+		t.Fatal("WriteKeyPair replaced existing files without overwrite")
+	}
+	assertKeyFile(t, privatePath, first.PrivateKey, 0o600)
+	assertKeyFile(t, publicPath, first.PublicKey, 0o644)
+
+	if err := pathwell.WriteKeyPair(second, privatePath, publicPath, true); err != nil {
+		t.Fatal(err)
+	}
+	assertKeyFile(t, privatePath, second.PrivateKey, 0o600)
+	assertKeyFile(t, publicPath, second.PublicKey, 0o644)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("directory has %d entries, want only the two key files", len(entries))
+	}
+}
+
+func TestWriteKeyPairRemovesPrivateKeyOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	privatePath := filepath.Join(dir, "agent.key")
+	// The public key's directory does not exist, so its write fails after
+	// the private key is in place
+	publicPath := filepath.Join(dir, "missing", "agent.pub")
+
+	kp, err := pathwell.GenerateKeyPairWithAlgorithm(pathwell.Ed25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pathwell.WriteKeyPair(kp, privatePath, publicPath, false); err == nil {
+		t.Fatal("WriteKeyPair succeeded without a public key directory")
+	}
+	if _, err := os.Stat(privatePath); !os.IsNotExist(err) {
+		t.Errorf("private key left behind: %v", err)
+	}
+}
+
+func assertKeyFile(t *testing.T, path, want string, mode os.FileMode) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != want {
+		t.Errorf("%s does not hold the expected key", filepath.Base(path))
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := info.Mode().Perm(); got != mode {
+		t.Errorf("%s mode = %o, want %o", filepath.Base(path), got, mode)
+	}
+}
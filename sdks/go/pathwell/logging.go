@@ -0,0 +1,87 @@
+package pathwell
+
+import (
+	"net/http"
+	"time"
+)
+
+// redactedValue replaces sensitive header values in logs
+const redactedValue = "[REDACTED]"
+
+// RequestLog describes one request attempt made by the client
+type RequestLog struct {
+	Method string
+	Path   string
+	// Headers are the headers sent to the proxy, with the signature redacted.
+	// Nil if the request failed before it was signed.
+	Headers http.Header
+	// StatusCode is zero if no response was received
+	StatusCode int
+	Latency    time.Duration
+	Err        error
+}
+
+// Logger receives a RequestLog for every request attempt, including
+// attempts that fail with an error. It is called concurrently when the
+// Client is shared between goroutines.
+type Logger interface {
+	LogRequest(entry RequestLog)
+}
+
+// LoggerFunc adapts a function to the Logger interface
+type LoggerFunc func(entry RequestLog)
+
+// LogRequest calls f(entry)
+func (f LoggerFunc) LogRequest(entry RequestLog) {
+	f(entry)
+}
+
+// redactHeaders returns a copy of h with the named headers redacted
+func redactHeaders(h http.Header, names []string) http.Header {
+	redacted := h.Clone()
+	for _, name := range names {
+		if redacted.Get(name) != "" {
+			redacted.Set(name, redactedValue)
+		}
+	}
+	return redacted
+}
+
+// sensitiveHeaders returns the headers redacted from logs: the signature,
+// Authorization, Cookie and any configured credential header
+func (c *Client) sensitiveHeaders() []string {
+	names := []string{c.headerNames.signature, "Authorization", "Cookie"}
+	for _, cred := range c.credentials {
+		names = append(names, cred.name)
+	}
+	return names
+}
+
+// logRequest reports a request attempt to the configured logger, if any
+func (c *Client) logRequest(
+	method string,
+	path string,
+	req *http.Request,
+	resp *http.Response,
+	err error,
+	start time.Time,
+) {
+	if c.logger == nil {
+		return
+	}
+
+	entry := RequestLog{
+		Method:  method,
+		Path:    path,
+		Latency: time.Since(start),
+		Err:     err,
+	}
+	if req != nil {
+		entry.Headers = redactHeaders(req.Header, c.sensitiveHeaders())
+	}
+	if resp != nil {
+		entry.StatusCode = resp.StatusCode
+	}
+
+	c.logger.LogRequest(entry)
+}
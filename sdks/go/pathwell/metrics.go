@@ -0,0 +1,15 @@
+package pathwell
+
+import "time"
+
+// Metrics receives request measurements from the client. It is implemented
+// by adapting a metrics library such as the Prometheus client, so the SDK
+// itself carries no metrics dependency. Implementations must be safe for
+// concurrent use.
+type Metrics interface {
+	// ObserveRequest records one request attempt. statusCode is zero when
+	// no response was received.
+	ObserveRequest(method string, statusCode int, duration time.Duration)
+	// IncSigningErrors records a request that could not be signed
+	IncSigningErrors()
+}
@@ -0,0 +1,28 @@
+package pathwell
+
+import "net/http"
+
+// Middleware wraps the transport used to send signed requests to the proxy.
+// Middlewares run after signing, so they must not alter the method, path or
+// body of a request.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc adapts a function to the http.RoundTripper interface
+type RoundTripperFunc func(req *http.Request) (*http.Response, error)
+
+// RoundTrip calls f(req)
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// chainMiddleware wraps base with middlewares so that the first middleware
+// is the outermost one
+func chainMiddleware(base http.RoundTripper, middlewares []Middleware) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		base = middlewares[i](base)
+	}
+	return base
+}
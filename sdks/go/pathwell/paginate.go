@@ -0,0 +1,142 @@
+package pathwell
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrStopPagination can be returned by a Paginate callback to stop early
+// without Paginate reporting an error
+var ErrStopPagination = errors.New("stop pagination")
+
+// Page is one page of a paginated listing, with its JSON body decoded into
+// a T
+type Page[T any] struct {
+	Data   T
+	Header http.Header
+	// URL is the request URL that produced the page, as passed to Paginate
+	// or returned by the NextPageFunc
+	URL string
+	// Number counts pages from 1
+	Number int
+}
+
+// NextPageFunc returns the request URL of the page after page, or "" when
+// page is the last one. Relative URLs are resolved like any request URL.
+type NextPageFunc[T any] func(page *Page[T]) (string, error)
+
+// Paginate GETs requestURL and every following page that next reports,
+// decoding each JSON body into a T and passing it to fn in order. Each page
+// is an ordinary call, so it is signed, retried and checked with
+// CheckStatus; the first failure, or error from next or fn, stops the
+// listing and is returned. ctx is checked between pages.
+func Paginate[T any](
+	ctx context.Context,
+	c *Client,
+	requestURL string,
+	headers map[string]string,
+	next NextPageFunc[T],
+	fn func(page *Page[T]) error,
+) error {
+	seen := make(map[string]bool)
+	for number := 1; requestURL != ""; number++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if seen[requestURL] {
+			return fmt.Errorf("pagination loop: %s was already fetched", requestURL)
+		}
+		seen[requestURL] = true
+
+		page := &Page[T]{URL: requestURL, Number: number}
+		header, err := c.CallJSONWithHeadersContext(ctx, http.MethodGet, requestURL, headers, nil, &page.Data)
+		if err != nil {
+			return fmt.Errorf("page %d: %w", number, err)
+		}
+		page.Header = header
+
+		if err := fn(page); err != nil {
+			if errors.Is(err, ErrStopPagination) {
+				return nil
+			}
+			return err
+		}
+
+		requestURL, err = next(page)
+		if err != nil {
+			return fmt.Errorf("page %d: %w", number, err)
+		}
+	}
+	return nil
+}
+
+// NextCursor paginates by query parameter: the next page is the current URL
+// with param set to the cursor extracted from the page, until cursor
+// returns ""
+func NextCursor[T any](param string, cursor func(page *Page[T]) string) NextPageFunc[T] {
+	return func(page *Page[T]) (string, error) {
+		token := cursor(page)
+		if token == "" {
+			return "", nil
+		}
+		parsed, err := url.Parse(page.URL)
+		if err != nil {
+			return "", fmt.Errorf("%w: %w", ErrInvalidURL, err)
+		}
+		query := parsed.Query()
+		query.Set(param, token)
+		parsed.RawQuery = query.Encode()
+		return parsed.String(), nil
+	}
+}
+
+// NextLink paginates by the rel="next" entry of the RFC 8288 Link response
+// header, as used by GitHub and many REST APIs, resolved against the
+// current page URL
+func NextLink[T any]() NextPageFunc[T] {
+	return func(page *Page[T]) (string, error) {
+		target := nextLink(page.Header.Values("Link"))
+		if target == "" {
+			return "", nil
+		}
+		base, err := url.Parse(page.URL)
+		if err != nil {
+			return "", fmt.Errorf("%w: %w", ErrInvalidURL, err)
+		}
+		ref, err := url.Parse(target)
+		if err != nil {
+			return "", fmt.Errorf("%w: invalid next link %q: %w", ErrInvalidURL, target, err)
+		}
+		return base.ResolveReference(ref).String(), nil
+	}
+}
+
+// nextLink returns the target of the first rel="next" link in the given
+// Link header values, or ""
+func nextLink(values []string) string {
+	for _, value := range values {
+		for _, link := range strings.Split(value, ",") {
+			target, params, ok := strings.Cut(strings.TrimSpace(link), ";")
+			target = strings.TrimSpace(target)
+			if !ok || !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			for _, param := range strings.Split(params, ";") {
+				name, rel, _ := strings.Cut(strings.TrimSpace(param), "=")
+				if !strings.EqualFold(name, "rel") {
+					continue
+				}
+				for _, r := range strings.Fields(strings.Trim(rel, `"`)) {
+					if strings.EqualFold(r, "next") {
+						return target[1 : len(target)-1]
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
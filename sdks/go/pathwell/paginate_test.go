@@ -0,0 +1,182 @@
+package pathwell_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pathwell/connect-go/pathwell"
+)
+
+type listPage struct {
+	Items []string `json:"items"`
+	Next  string   `json:"next"`
+}
+
+// pagedServer serves three pages of items, linked both by a "next" cursor in
+// the body and by a Link header, and records the request URIs it served
+func pagedServer(t *testing.T) (*httptest.Server, func() []string) {
+	t.Helper()
+	pages := map[string]listPage{
+		"":   {Items: []string{"a", "b"}, Next: "p2"},
+		"p2": {Items: []string{"c", "d"}, Next: "p3"},
+		"p3": {Items: []string{"e"}},
+	}
+	var mu sync.Mutex
+	var served []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		served = append(served, r.URL.RequestURI())
+		mu.Unlock()
+
+		page, ok := pages[r.URL.Query().Get("cursor")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if page.Next != "" {
+			w.Header().Add("Link", `</items?cursor=1>; rel="prev"`)
+			w.Header().Add("Link", `</items?cursor=`+page.Next+`>; rel="next"`)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(page)
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), served...)
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	cursor := pathwell.NextCursor("cursor", func(page *pathwell.Page[listPage]) string { return page.Data.Next })
+	for name, next := range map[string]pathwell.NextPageFunc[listPage]{
+		"NextCursor": cursor,
+		"NextLink":   pathwell.NextLink[listPage](),
+	} {
+		t.Run(name, func(t *testing.T) {
+			server, served := pagedServer(t)
+			client := newStubClient(t, server.URL, pathwell.ClientOptions{})
+
+			var items []string
+			var numbers []int
+			err := pathwell.Paginate(context.Background(), client, "https://api.example.com/items", nil, next,
+				func(page *pathwell.Page[listPage]) error {
+					items = append(items, page.Data.Items...)
+					numbers = append(numbers, page.Number)
+					return nil
+				})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := []string{"a", "b", "c", "d", "e"}; !reflect.DeepEqual(items, want) {
+				t.Errorf("items = %v, want %v", items, want)
+			}
+			if want := []int{1, 2, 3}; !reflect.DeepEqual(numbers, want) {
+				t.Errorf("page numbers = %v, want %v", numbers, want)
+			}
+			// The last page has no next token, so nothing is fetched after it
+			if want := []string{"/items", "/items?cursor=p2", "/items?cursor=p3"}; !reflect.DeepEqual(served(), want) {
+				t.Errorf("served %v, want %v", served(), want)
+			}
+		})
+	}
+}
+
+func TestPaginateStops(t *testing.T) {
+	cursor := pathwell.NextCursor("cursor", func(page *pathwell.Page[listPage]) string { return page.Data.Next })
+	errCallback := errors.New("callback failed")
+
+	t.Run("callback error", func(t *testing.T) {
+		server, served := pagedServer(t)
+		client := newStubClient(t, server.URL, pathwell.ClientOptions{})
+		err := pathwell.Paginate(context.Background(), client, "https://api.example.com/items", nil, cursor,
+			func(page *pathwell.Page[listPage]) error {
+				if page.Number == 2 {
+					return errCallback
+				}
+				return nil
+			})
+		if !errors.Is(err, errCallback) {
+			t.Errorf("error = %v, want the callback's error", err)
+		}
+		if n := len(served()); n != 2 {
+			t.Errorf("fetched %d pages, want 2", n)
+		}
+	})
+
+	t.Run("ErrStopPagination", func(t *testing.T) {
+		server, served := pagedServer(t)
+		client := newStubClient(t, server.URL, pathwell.ClientOptions{})
+		err := pathwell.Paginate(context.Background(), client, "https://api.example.com/items", nil, cursor,
+			func(page *pathwell.Page[listPage]) error { return pathwell.ErrStopPagination })
+		if err != nil {
+			t.Errorf("error = %v, want nil", err)
+		}
+		if n := len(served()); n != 1 {
+			t.Errorf("fetched %d pages, want 1", n)
+		}
+	})
+
+	t.Run("context canceled between pages", func(t *testing.T) {
+		server, served := pagedServer(t)
+		client := newStubClient(t, server.URL, pathwell.ClientOptions{})
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		err := pathwell.Paginate(ctx, client, "https://api.example.com/items", nil, cursor,
+			func(page *pathwell.Page[listPage]) error {
+				cancel()
+				return nil
+			})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("error = %v, want context.Canceled", err)
+		}
+		if n := len(served()); n != 1 {
+			t.Errorf("fetched %d pages, want 1", n)
+		}
+	})
+
+	t.Run("next error", func(t *testing.T) {
+		server, _ := pagedServer(t)
+		client := newStubClient(t, server.URL, pathwell.ClientOptions{})
+		failing := func(page *pathwell.Page[listPage]) (string, error) { return "", errCallback }
+		err := pathwell.Paginate(context.Background(), client, "https://api.example.com/items", nil, failing,
+			func(page *pathwell.Page[listPage]) error { return nil })
+		if !errors.Is(err, errCallback) || !strings.HasPrefix(err.Error(), "page 1: ") {
+			t.Errorf("error = %v, want the next error for page 1", err)
+		}
+	})
+
+	t.Run("status error", func(t *testing.T) {
+		server, _ := pagedServer(t)
+		client := newStubClient(t, server.URL, pathwell.ClientOptions{})
+		missing := pathwell.NextCursor("cursor", func(page *pathwell.Page[listPage]) string { return "gone" })
+		err := pathwell.Paginate(context.Background(), client, "https://api.example.com/items", nil, missing,
+			func(page *pathwell.Page[listPage]) error { return nil })
+		var apiErr *pathwell.APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound || !strings.HasPrefix(err.Error(), "page 2: ") {
+			t.Errorf("error = %v, want a 404 *APIError for page 2", err)
+		}
+	})
+
+	t.Run("loop", func(t *testing.T) {
+		server, served := pagedServer(t)
+		client := newStubClient(t, server.URL, pathwell.ClientOptions{})
+		same := func(page *pathwell.Page[listPage]) (string, error) { return page.URL, nil }
+		err := pathwell.Paginate(context.Background(), client, "https://api.example.com/items", nil, same,
+			func(page *pathwell.Page[listPage]) error { return nil })
+		if err == nil || !strings.Contains(err.Error(), "pagination loop") {
+			t.Errorf("error = %v, want a pagination loop", err)
+		}
+		if n := len(served()); n != 1 {
+			t.Errorf("fetched %d pages, want 1", n)
+		}
+	})
+}
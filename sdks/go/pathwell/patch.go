@@ -0,0 +1,48 @@
+package pathwell
+
+import (
+	"context"
+	"net/http"
+)
+
+// Media types for PATCH bodies, for upstreams that dispatch on them
+const (
+	// MergePatchContentType is JSON Merge Patch (RFC 7396)
+	MergePatchContentType = "application/merge-patch+json"
+	// JSONPatchContentType is JSON Patch (RFC 6902)
+	JSONPatchContentType = "application/json-patch+json"
+)
+
+// PatchJSONMerge sends a PATCH whose body is a JSON Merge Patch document,
+// with Content-Type application/merge-patch+json. body is encoded like any
+// other Call body; a Content-Type in headers takes precedence.
+func (c *Client) PatchJSONMerge(url string, headers map[string]string, body interface{}) (*http.Response, error) {
+	return c.PatchJSONMergeContext(context.Background(), url, headers, body)
+}
+
+// PatchJSONMergeContext is PatchJSONMerge bound to ctx
+func (c *Client) PatchJSONMergeContext(
+	ctx context.Context,
+	url string,
+	headers map[string]string,
+	body interface{},
+) (*http.Response, error) {
+	return c.CallContext(ctx, "PATCH", url, withDefaultHeader(headers, "Content-Type", MergePatchContentType), body)
+}
+
+// PatchJSONPatch sends a PATCH whose body is a JSON Patch array of
+// operations, with Content-Type application/json-patch+json. body is encoded
+// like any other Call body; a Content-Type in headers takes precedence.
+func (c *Client) PatchJSONPatch(url string, headers map[string]string, body interface{}) (*http.Response, error) {
+	return c.PatchJSONPatchContext(context.Background(), url, headers, body)
+}
+
+// PatchJSONPatchContext is PatchJSONPatch bound to ctx
+func (c *Client) PatchJSONPatchContext(
+	ctx context.Context,
+	url string,
+	headers map[string]string,
+	body interface{},
+) (*http.Response, error) {
+	return c.CallContext(ctx, "PATCH", url, withDefaultHeader(headers, "Content-Type", JSONPatchContentType), body)
+}
@@ -0,0 +1,256 @@
+// Package pathwelltest provides a stand-in Pathwell proxy for tests. It
+// verifies request signatures exactly as the real proxy does, so an
+// integration test can exercise a pathwell.Client end to end without
+// reimplementing the protocol:
+//
+//	keyPair, _ := pathwell.GenerateKeyPairWithAlgorithm(pathwell.Ed25519)
+//	proxy := pathwelltest.NewTestProxy(keyPair.PublicKey, pathwelltest.ProxyOptions{Echo: true})
+//	defer proxy.Close()
+//
+//	client, _ := pathwell.NewClient(pathwell.ClientOptions{
+//		AgentID:       "agent-123",
+//		PrivateKeyPEM: keyPair.PrivateKey,
+//		ProxyURL:      proxy.URL,
+//	})
+package pathwelltest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pathwell/connect-go/pathwell"
+)
+
+// ProxyOptions configures a TestProxy
+type ProxyOptions struct {
+	// Handler serves requests whose signature verified. When nil, the proxy
+	// answers 200 with an empty body, or echoes the request if Echo is set.
+	Handler http.Handler
+	// Echo answers verified requests with a JSON description of what the
+	// proxy received (see EchoResponse). Ignored when Handler is set.
+	Echo bool
+	// MaxSkew rejects timestamps further than this from the local clock.
+	// Zero disables the check.
+	MaxSkew time.Duration
+
+	// Header names, matching the overrides in pathwell.ClientOptions. Empty
+	// fields keep the pathwell.Default*Header names.
+	AgentIDHeader       string
+	SignatureHeader     string
+	TimestampHeader     string
+	NonceHeader         string
+	KeyIDHeader         string
+	TargetHeader        string
+	SignedHeadersHeader string
+	SignedHostHeader    string
+}
+
+// header returns name, or fallback when name is empty
+func header(name, fallback string) string {
+	if name == "" {
+		return fallback
+	}
+	return name
+}
+
+// Request is a verified request as the proxy received it
+type Request struct {
+	Method  string
+	Path    string
+	Header  http.Header
+	Body    []byte
+	AgentID string
+	Target  string
+}
+
+// EchoResponse is the body returned for verified requests in Echo mode
+type EchoResponse struct {
+	Method  string              `json:"method"`
+	Path    string              `json:"path"`
+	Headers map[string][]string `json:"headers"`
+	Body    string              `json:"body"`
+	AgentID string              `json:"agent_id"`
+	Target  string              `json:"target,omitempty"`
+}
+
+// TestProxy is an httptest.Server that checks X-Pathwell-Signature against
+// one agent public key. Requests that fail verification, reuse a nonce or
+// fall outside MaxSkew get a 401 with a JSON {"error": "..."} body.
+type TestProxy struct {
+	*httptest.Server
+
+	publicKeyPEM string
+	options      ProxyOptions
+
+	mu       sync.Mutex
+	requests []Request
+	nonces   map[string]bool
+}
+
+// NewTestProxy starts a TestProxy that verifies signatures with
+// publicKeyPEM. Close it when done.
+func NewTestProxy(publicKeyPEM string, options ProxyOptions) *TestProxy {
+	p := &TestProxy{
+		publicKeyPEM: publicKeyPEM,
+		options:      options,
+		nonces:       make(map[string]bool),
+	}
+	p.Server = httptest.NewServer(http.HandlerFunc(p.serveHTTP))
+	return p
+}
+
+// Requests returns the requests that passed verification, in arrival order
+func (p *TestProxy) Requests() []Request {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Request(nil), p.requests...)
+}
+
+// serveHTTP verifies the request and hands it to the configured handler
+func (p *TestProxy) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		reject(w, fmt.Errorf("failed to read body: %w", err))
+		return
+	}
+
+	if err := p.verify(r, body); err != nil {
+		reject(w, err)
+		return
+	}
+
+	req := Request{
+		Method:  r.Method,
+		Path:    r.URL.RequestURI(),
+		Header:  r.Header.Clone(),
+		Body:    body,
+		AgentID: r.Header.Get(header(p.options.AgentIDHeader, pathwell.DefaultAgentIDHeader)),
+		Target:  r.Header.Get(header(p.options.TargetHeader, pathwell.DefaultTargetHeader)),
+	}
+	p.mu.Lock()
+	p.requests = append(p.requests, req)
+	p.mu.Unlock()
+
+	switch {
+	case p.options.Handler != nil:
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		p.options.Handler.ServeHTTP(w, r)
+	case p.options.Echo:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(EchoResponse{
+			Method:  req.Method,
+			Path:    req.Path,
+			Headers: req.Header,
+			Body:    string(body),
+			AgentID: req.AgentID,
+			Target:  req.Target,
+		})
+	default:
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// verify checks the signing headers, the signature, the timestamp window
+// and nonce reuse
+func (p *TestProxy) verify(r *http.Request, body []byte) error {
+	o := p.options
+	agentIDHeader := header(o.AgentIDHeader, pathwell.DefaultAgentIDHeader)
+	if r.Header.Get(agentIDHeader) == "" {
+		return fmt.Errorf("missing %s", agentIDHeader)
+	}
+	signature := r.Header.Get(header(o.SignatureHeader, pathwell.DefaultSignatureHeader))
+	timestamp := r.Header.Get(header(o.TimestampHeader, pathwell.DefaultTimestampHeader))
+	nonce := r.Header.Get(header(o.NonceHeader, pathwell.DefaultNonceHeader))
+	if signature == "" || timestamp == "" || nonce == "" {
+		return fmt.Errorf("missing signature, timestamp or nonce")
+	}
+
+	var headers map[string]string
+	if names := r.Header.Get(header(o.SignedHeadersHeader, pathwell.DefaultSignedHeadersHeader)); names != "" {
+		headers = make(map[string]string)
+		for _, name := range strings.Split(names, ";") {
+			headers[name] = r.Header.Get(name)
+		}
+	}
+
+	input := pathwell.SignatureInput{
+		Method:         r.Method,
+		Path:           r.URL.RequestURI(),
+		Body:           body,
+		Timestamp:      timestamp,
+		Nonce:          nonce,
+		KeyID:          r.Header.Get(header(o.KeyIDHeader, pathwell.DefaultKeyIDHeader)),
+		IdempotencyKey: r.Header.Get(pathwell.IdempotencyKeyHeader),
+		Host:           r.Header.Get(header(o.SignedHostHeader, pathwell.DefaultSignedHostHeader)),
+		Headers:        headers,
+	}
+	if err := input.Verify(p.publicKeyPEM, signature); err != nil {
+		return err
+	}
+
+	// A body digest header must match the body that was signed
+	if err := checkBodyDigest(r.Header, body); err != nil {
+		return err
+	}
+
+	// A signed host must be the upstream the request is forwarded to
+	if input.Host != "" {
+		target, err := pathwell.CanonicalHost(r.Header.Get(header(o.TargetHeader, pathwell.DefaultTargetHeader)))
+		if err != nil || target != input.Host {
+			return fmt.Errorf("signed host %q does not match the target", input.Host)
+		}
+	}
+
+	if p.options.MaxSkew > 0 {
+		unix, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid timestamp %q", timestamp)
+		}
+		skew := time.Since(time.Unix(unix, 0))
+		if skew < 0 {
+			skew = -skew
+		}
+		if skew > p.options.MaxSkew {
+			return fmt.Errorf("timestamp %s is outside the allowed skew of %s", timestamp, p.options.MaxSkew)
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.nonces[nonce] {
+		return fmt.Errorf("nonce %s was already used", nonce)
+	}
+	p.nonces[nonce] = true
+	return nil
+}
+
+// checkBodyDigest compares the X-Content-SHA256 and Digest headers, when
+// present, with the SHA-256 of body
+func checkBodyDigest(header http.Header, body []byte) error {
+	sum := sha256.Sum256(body)
+	if value := header.Get(pathwell.ContentSHA256Header); value != "" && !strings.EqualFold(value, hex.EncodeToString(sum[:])) {
+		return fmt.Errorf("%s does not match the body", pathwell.ContentSHA256Header)
+	}
+	if value := header.Get(pathwell.DigestHeader); value != "" && value != "SHA-256="+base64.StdEncoding.EncodeToString(sum[:]) {
+		return fmt.Errorf("%s does not match the body", pathwell.DigestHeader)
+	}
+	return nil
+}
+
+// reject answers 401 with the verification error
+func reject(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnauthorized)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
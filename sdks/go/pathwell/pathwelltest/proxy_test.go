@@ -0,0 +1,284 @@
+package pathwelltest_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pathwell/connect-go/pathwell"
+	"github.com/pathwell/connect-go/pathwell/pathwelltest"
+)
+
+// newClient starts a proxy for a fresh key pair and a client signing for
+// it, with options applied on top of the key and proxy URL
+func newClient(t *testing.T, alg pathwell.KeyAlgorithm, proxyOptions pathwelltest.ProxyOptions, options pathwell.ClientOptions) (*pathwelltest.TestProxy, *pathwell.Client) {
+	t.Helper()
+	keyPair, err := pathwell.GenerateKeyPairWithAlgorithm(alg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxy := pathwelltest.NewTestProxy(keyPair.PublicKey, proxyOptions)
+	t.Cleanup(proxy.Close)
+
+	options.AgentID = "agent-123"
+	options.PrivateKeyPEM = keyPair.PrivateKey
+	options.ProxyURL = proxy.URL
+	client, err := pathwell.NewClient(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return proxy, client
+}
+
+// status makes a call and returns its status and body
+func status(t *testing.T, client *pathwell.Client, method, url string, headers map[string]string, body interface{}) (int, string) {
+	t.Helper()
+	resp, err := client.Call(method, url, headers, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(data)
+}
+
+func TestProxyAcceptsClientRequests(t *testing.T) {
+	options := map[string]pathwell.ClientOptions{
+		"plain":           {},
+		"key id":          {KeyID: "key-1"},
+		"idempotency":     {IdempotencyKeys: true},
+		"signed headers":  {SignedHeaders: []string{"Content-Type", "X-Tenant-ID"}},
+		"signed host":     {IncludeHostInSignature: true},
+		"hex digest":      {SendBodyDigestHeader: true},
+		"base64 digest":   {SendBodyDigestHeader: true, BodyDigestEncoding: pathwell.DigestBase64},
+		"compressed body": {CompressRequestBody: true, CompressMinSize: 1},
+		"path prefix":     {PathPrefix: "/pathwell", SignPathPrefix: true},
+	}
+	for _, alg := range []pathwell.KeyAlgorithm{pathwell.RSA2048, pathwell.Ed25519, pathwell.ECDSAP256} {
+		for name, opts := range options {
+			opts.BaseURL = "https://api.example.com/v1/"
+			proxy, client := newClient(t, alg, pathwelltest.ProxyOptions{}, opts)
+			headers := map[string]string{"Content-Type": "application/json", "X-Tenant-ID": "t1"}
+
+			calls := []struct {
+				method string
+				url    string
+				body   interface{}
+			}{
+				{"GET", "items?q=a b&tag=é", nil},
+				{"POST", "items", map[string]string{"name": "<widget>"}},
+				{"put", "items/1", "replacement"},
+				{"DELETE", "items/1", nil},
+				{"PATCH", "items/1", map[string]int{"count": 2}},
+			}
+			for _, c := range calls {
+				h := headers
+				if c.method == "POST" {
+					h = map[string]string{"Content-Type": "application/json", "Idempotency-Key": "caller-key"}
+				}
+				if code, body := status(t, client, c.method, c.url, h, c.body); code != http.StatusOK {
+					t.Errorf("%s, %s: %s %s: status %d: %s", alg, name, c.method, c.url, code, body)
+				}
+			}
+			if got := len(proxy.Requests()); got != len(calls) {
+				t.Errorf("%s, %s: %d requests recorded, want %d", alg, name, got, len(calls))
+			}
+		}
+	}
+}
+
+func TestProxyEcho(t *testing.T) {
+	_, client := newClient(t, pathwell.Ed25519, pathwelltest.ProxyOptions{Echo: true},
+		pathwell.ClientOptions{TargetURL: "https://api.example.com"})
+
+	code, body := status(t, client, "POST", "/v1/items?x=1", map[string]string{"Content-Type": "text/plain"}, "hello")
+	if code != http.StatusOK {
+		t.Fatalf("status %d: %s", code, body)
+	}
+	var echo pathwelltest.EchoResponse
+	if err := json.Unmarshal([]byte(body), &echo); err != nil {
+		t.Fatal(err)
+	}
+	if echo.Method != "POST" || echo.Path != "/v1/items?x=1" || echo.Body != "hello" ||
+		echo.AgentID != "agent-123" || echo.Target != "https://api.example.com" {
+		t.Fatalf("echo = %+v", echo)
+	}
+}
+
+func TestProxyRejects(t *testing.T) {
+	past := func() time.Time { return time.Now().Add(-time.Hour) }
+	tests := []struct {
+		name    string
+		proxy   pathwelltest.ProxyOptions
+		options pathwell.ClientOptions
+		want    string
+	}{
+		{
+			name:    "tampered body",
+			options: pathwell.ClientOptions{Middlewares: []pathwell.Middleware{rewriteBody("tampered")}},
+			want:    "invalid signature",
+		},
+		{
+			name: "tampered idempotency key",
+			options: pathwell.ClientOptions{IdempotencyKeys: true, PostSign: func(req *http.Request) error {
+				req.Header.Set(pathwell.IdempotencyKeyHeader, "swapped")
+				return nil
+			}},
+			want: "invalid signature",
+		},
+		{
+			name: "unsigned idempotency key",
+			options: pathwell.ClientOptions{PostSign: func(req *http.Request) error {
+				req.Header.Set(pathwell.IdempotencyKeyHeader, "added")
+				return nil
+			}},
+			want: "invalid signature",
+		},
+		{
+			name: "tampered signed header",
+			options: pathwell.ClientOptions{SignedHeaders: []string{"Content-Type"}, PostSign: func(req *http.Request) error {
+				req.Header.Set("Content-Type", "text/html")
+				return nil
+			}},
+			want: "invalid signature",
+		},
+		{
+			name: "digest mismatch",
+			options: pathwell.ClientOptions{SendBodyDigestHeader: true, PostSign: func(req *http.Request) error {
+				req.Header.Set(pathwell.ContentSHA256Header, strings.Repeat("0", 64))
+				return nil
+			}},
+			want: "does not match the body",
+		},
+		{
+			name: "signed host differs from target",
+			options: pathwell.ClientOptions{IncludeHostInSignature: true, PostSign: func(req *http.Request) error {
+				req.Header.Set(pathwell.DefaultTargetHeader, "https://evil.example.com")
+				return nil
+			}},
+			want: "does not match the target",
+		},
+		{
+			name:    "stale timestamp",
+			proxy:   pathwelltest.ProxyOptions{MaxSkew: time.Minute},
+			options: pathwell.ClientOptions{NowFunc: past},
+			want:    "outside the allowed skew",
+		},
+		{
+			name: "missing signature",
+			options: pathwell.ClientOptions{PostSign: func(req *http.Request) error {
+				req.Header.Del(pathwell.DefaultSignatureHeader)
+				return nil
+			}},
+			want: "missing signature",
+		},
+		{
+			name:    "renamed headers the proxy does not know",
+			options: pathwell.ClientOptions{AgentIDHeader: "X-Edge-Agent"},
+			want:    "missing X-Pathwell-Agent-ID",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.options.TargetURL = "https://api.example.com"
+			proxy, client := newClient(t, pathwell.Ed25519, tt.proxy, tt.options)
+			code, body := status(t, client, "POST", "/v1/items", map[string]string{"Content-Type": "application/json"}, "payload")
+			if code != http.StatusUnauthorized || !strings.Contains(body, tt.want) {
+				t.Fatalf("status %d, body %s; want 401 containing %q", code, body, tt.want)
+			}
+			if len(proxy.Requests()) != 0 {
+				t.Fatal("a rejected request was recorded")
+			}
+		})
+	}
+}
+
+func TestProxyRejectsWrongKey(t *testing.T) {
+	other, err := pathwell.GenerateKeyPairWithAlgorithm(pathwell.Ed25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxy := pathwelltest.NewTestProxy(other.PublicKey, pathwelltest.ProxyOptions{})
+	defer proxy.Close()
+	keyPair, err := pathwell.GenerateKeyPairWithAlgorithm(pathwell.Ed25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := pathwell.NewClient(pathwell.ClientOptions{AgentID: "agent-123", PrivateKeyPEM: keyPair.PrivateKey, ProxyURL: proxy.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code, _ := status(t, client, "GET", "https://api.example.com/x", nil, nil); code != http.StatusUnauthorized {
+		t.Fatalf("status %d, want 401", code)
+	}
+}
+
+func TestProxyRejectsReusedNonce(t *testing.T) {
+	_, client := newClient(t, pathwell.Ed25519, pathwelltest.ProxyOptions{}, pathwell.ClientOptions{
+		TargetURL:   "https://api.example.com",
+		IDGenerator: func() (string, error) { return "fixed-nonce", nil },
+	})
+	if code, body := status(t, client, "GET", "/x", nil, nil); code != http.StatusOK {
+		t.Fatalf("first request: status %d: %s", code, body)
+	}
+	if code, body := status(t, client, "GET", "/x", nil, nil); code != http.StatusUnauthorized || !strings.Contains(body, "already used") {
+		t.Fatalf("replayed nonce: status %d: %s", code, body)
+	}
+}
+
+func TestProxyCustomHeaderNames(t *testing.T) {
+	names := pathwell.ClientOptions{
+		AgentIDHeader:       "X-Edge-Agent",
+		SignatureHeader:     "X-Edge-Signature",
+		TimestampHeader:     "X-Edge-Timestamp",
+		NonceHeader:         "X-Edge-Nonce",
+		KeyIDHeader:         "X-Edge-Key-ID",
+		TargetHeader:        "X-Edge-Target",
+		SignedHeadersHeader: "X-Edge-Signed-Headers",
+		SignedHostHeader:    "X-Edge-Signed-Host",
+	}
+	options := names
+	options.TargetURL = "https://api.example.com"
+	options.KeyID = "key-1"
+	options.SignedHeaders = []string{"Content-Type"}
+	options.IncludeHostInSignature = true
+	proxy, client := newClient(t, pathwell.Ed25519, pathwelltest.ProxyOptions{
+		AgentIDHeader:       names.AgentIDHeader,
+		SignatureHeader:     names.SignatureHeader,
+		TimestampHeader:     names.TimestampHeader,
+		NonceHeader:         names.NonceHeader,
+		KeyIDHeader:         names.KeyIDHeader,
+		TargetHeader:        names.TargetHeader,
+		SignedHeadersHeader: names.SignedHeadersHeader,
+		SignedHostHeader:    names.SignedHostHeader,
+	}, options)
+
+	code, body := status(t, client, "POST", "/v1/items", map[string]string{"Content-Type": "application/json"}, map[string]int{"n": 1})
+	if code != http.StatusOK {
+		t.Fatalf("status %d: %s", code, body)
+	}
+	req := proxy.Requests()[0]
+	if req.AgentID != "agent-123" || req.Target != "https://api.example.com" {
+		t.Fatalf("AgentID %q, Target %q read from the default names", req.AgentID, req.Target)
+	}
+	for name := range req.Header {
+		if strings.HasPrefix(name, "X-Pathwell-") {
+			t.Errorf("%s was sent", name)
+		}
+	}
+}
+
+// rewriteBody is a middleware replacing the request body after signing
+func rewriteBody(body string) pathwell.Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return pathwell.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			req = req.Clone(req.Context())
+			req.Body = io.NopCloser(strings.NewReader(body))
+			req.ContentLength = int64(len(body))
+			return next.RoundTrip(req)
+		})
+	}
+}
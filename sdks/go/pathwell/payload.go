@@ -0,0 +1,29 @@
+package pathwell
+
+import (
+	"crypto"
+	"fmt"
+)
+
+// SignPayload signs arbitrary bytes with the agent's key, using the same
+// algorithm as request signatures: RSASSA-PKCS1-v1_5 over the SHA-256 digest
+// for RSA keys, ECDSA over the same digest for P-256 keys (ASN.1 DER) and
+// Ed25519 directly for Ed25519 keys. It returns the raw
+// signature; no request framing, timestamp or nonce is added, so callers
+// that need replay protection must put that in data themselves.
+func SignPayload(signer crypto.Signer, data []byte) ([]byte, error) {
+	signature, err := signPayload(signer, data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSigning, err)
+	}
+	return signature, nil
+}
+
+// VerifyPayload checks a signature produced by SignPayload against the
+// agent's public key, as returned by ParsePublicKey
+func VerifyPayload(publicKey crypto.PublicKey, data, signature []byte) error {
+	if err := verifyPayload(publicKey, data, signature); err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+	return nil
+}
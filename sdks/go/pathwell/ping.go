@@ -0,0 +1,60 @@
+package pathwell
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// defaultHealthPath is the proxy's health endpoint
+const defaultHealthPath = "/health"
+
+// Errors returned, wrapped, by Ping to classify a failure. Ping also wraps
+// ErrProxyUnreachable around every transport failure.
+var (
+	// ErrAuthRejected means the proxy answered 401 or 403, so the agent ID,
+	// key or clock is wrong
+	ErrAuthRejected = errors.New("authentication rejected by proxy")
+	// ErrProxyUnhealthy means the proxy answered with any other non-2xx
+	// status
+	ErrProxyUnhealthy = errors.New("proxy unhealthy")
+)
+
+// Ping sends a signed GET to the proxy's health path (HealthPath, "/health"
+// by default) and reports whether the proxy is reachable and accepts the
+// agent's signature. It returns nil when healthy, or an error wrapping
+// ErrProxyUnreachable, ErrAuthRejected or ErrProxyUnhealthy; HTTP failures
+// also wrap the *APIError. Ping makes a single attempt and ignores BaseURL,
+// so it is suitable for readiness probes.
+func (c *Client) Ping(ctx context.Context) error {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	resp, err := c.sendOnce(ctx, &preparedRequest{
+		method: http.MethodGet,
+		path:   c.pathPrefix + c.healthPath,
+	})
+	if err != nil {
+		// Anything but a transport error, such as a signing error, happened
+		// locally
+		if errors.Is(err, ErrTransport) && !errors.Is(err, ErrProxyUnreachable) {
+			return fmt.Errorf("%w: %w", ErrProxyUnreachable, err)
+		}
+		return err
+	}
+
+	statusErr := CheckStatus(resp)
+	switch {
+	case statusErr == nil:
+		discardBody(resp)
+		return nil
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("%w: %w", ErrAuthRejected, statusErr)
+	default:
+		return fmt.Errorf("%w: %w", ErrProxyUnhealthy, statusErr)
+	}
+}
@@ -0,0 +1,113 @@
+package pathwell_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pathwell/connect-go/pathwell"
+	"github.com/pathwell/connect-go/pathwell/pathwelltest"
+)
+
+func TestPing(t *testing.T) {
+	tests := []struct {
+		status int
+		want   error
+	}{
+		{http.StatusOK, nil},
+		{http.StatusNoContent, nil},
+		{http.StatusUnauthorized, pathwell.ErrAuthRejected},
+		{http.StatusForbidden, pathwell.ErrAuthRejected},
+		{http.StatusNotFound, pathwell.ErrProxyUnhealthy},
+		{http.StatusServiceUnavailable, pathwell.ErrProxyUnhealthy},
+	}
+	for _, tt := range tests {
+		var path string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path = r.URL.Path
+			w.WriteHeader(tt.status)
+		}))
+		client := newStubClient(t, server.URL, pathwell.ClientOptions{BaseURL: "https://api.example.com/v1/"})
+		err := client.Ping(context.Background())
+		server.Close()
+
+		if tt.want == nil && err != nil || tt.want != nil && !errors.Is(err, tt.want) {
+			t.Errorf("status %d: error = %v, want %v", tt.status, err, tt.want)
+		}
+		var apiErr *pathwell.APIError
+		if tt.want != nil && (!errors.As(err, &apiErr) || apiErr.StatusCode != tt.status) {
+			t.Errorf("status %d: error %v does not wrap the APIError", tt.status, err)
+		}
+		if path != "/health" {
+			t.Errorf("status %d: pinged %q, want /health", tt.status, path)
+		}
+	}
+}
+
+func TestPingUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedURL := "http://" + listener.Addr().String()
+	listener.Close()
+
+	client := newStubClient(t, closedURL, pathwell.ClientOptions{})
+	if err := client.Ping(context.Background()); !errors.Is(err, pathwell.ErrProxyUnreachable) {
+		t.Fatalf("error = %v, want ErrProxyUnreachable", err)
+	}
+}
+
+func TestPingHealthPath(t *testing.T) {
+	for healthPath, want := range map[string]string{
+		"":          "/health",
+		"healthz":   "/healthz",
+		"/healthz":  "/healthz",
+		"ready/all": "/ready/all",
+	} {
+		var got string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r.URL.Path
+		}))
+		client := newStubClient(t, server.URL, pathwell.ClientOptions{HealthPath: healthPath, PathPrefix: "/pathwell"})
+		err := client.Ping(context.Background())
+		server.Close()
+		if err != nil {
+			t.Errorf("HealthPath %q: %v", healthPath, err)
+		}
+		if got != "/pathwell"+want {
+			t.Errorf("HealthPath %q: pinged %q, want %q", healthPath, got, "/pathwell"+want)
+		}
+	}
+
+	keyPair, err := pathwell.GenerateKeyPairWithAlgorithm(pathwell.Ed25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, healthPath := range []string{"http://elsewhere.example.com/health", "//elsewhere.example.com/health"} {
+		_, err := pathwell.NewClient(pathwell.ClientOptions{AgentID: "agent-123", PrivateKeyPEM: keyPair.PrivateKey, HealthPath: healthPath})
+		if err == nil {
+			t.Errorf("HealthPath %q accepted", healthPath)
+		}
+	}
+}
+
+// Ping is signed, so a verifying proxy accepts it, and rejects a client
+// signing with another key
+func TestPingVerifies(t *testing.T) {
+	proxy, client := newProxyClient(t, pathwelltest.ProxyOptions{}, pathwell.ClientOptions{HealthPath: "health"})
+	if err := client.Ping(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := proxy.Requests()[0].Path; got != "/health" {
+		t.Fatalf("pinged %q", got)
+	}
+
+	stranger := newStubClient(t, proxy.URL, pathwell.ClientOptions{})
+	if err := stranger.Ping(context.Background()); !errors.Is(err, pathwell.ErrAuthRejected) {
+		t.Fatalf("error = %v, want ErrAuthRejected", err)
+	}
+}
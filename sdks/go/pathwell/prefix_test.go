@@ -0,0 +1,99 @@
+package pathwell_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"testing"
+
+	"github.com/pathwell/connect-go/pathwell"
+	"github.com/pathwell/connect-go/pathwell/pathwelltest"
+)
+
+// newGateway mounts proxy under /pathwell, stripping the prefix before
+// forwarding when strip is set
+func newGateway(t *testing.T, proxy *pathwelltest.TestProxy, strip bool) *httptest.Server {
+	t.Helper()
+	proxyURL, err := url.Parse(proxy.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var handler http.Handler = httputil.NewSingleHostReverseProxy(proxyURL)
+	if strip {
+		handler = http.StripPrefix("/pathwell", handler)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/pathwell/", handler)
+	gateway := httptest.NewServer(mux)
+	t.Cleanup(gateway.Close)
+	return gateway
+}
+
+func TestPathPrefix(t *testing.T) {
+	tests := []struct {
+		name       string
+		prefix     string
+		sign       bool
+		strip      bool
+		wantStatus int
+		wantPath   string
+	}{
+		{"stripped, unsigned", "/pathwell", false, true, http.StatusOK, "/v1/users?page=2"},
+		{"slashes normalized", "pathwell/", false, true, http.StatusOK, "/v1/users?page=2"},
+		{"kept, signed", "/pathwell", true, false, http.StatusOK, "/pathwell/v1/users?page=2"},
+		{"stripped, but signed", "/pathwell", true, true, http.StatusUnauthorized, ""},
+		{"kept, but unsigned", "/pathwell", false, false, http.StatusUnauthorized, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyPair, err := pathwell.GenerateKeyPairWithAlgorithm(pathwell.Ed25519)
+			if err != nil {
+				t.Fatal(err)
+			}
+			proxy := pathwelltest.NewTestProxy(keyPair.PublicKey, pathwelltest.ProxyOptions{})
+			defer proxy.Close()
+			gateway := newGateway(t, proxy, tt.strip)
+
+			client, err := pathwell.NewClient(pathwell.ClientOptions{
+				AgentID:        "agent-123",
+				PrivateKeyPEM:  keyPair.PrivateKey,
+				ProxyURL:       gateway.URL,
+				PathPrefix:     tt.prefix,
+				SignPathPrefix: tt.sign,
+				BaseURL:        "https://api.example.com/v1/",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := client.Get("users?page=2", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			requests := proxy.Requests()
+			if len(requests) != 1 || requests[0].Path != tt.wantPath {
+				t.Fatalf("proxy saw %+v, want %s", requests, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestPathPrefixRejectsQuery(t *testing.T) {
+	keyPair, err := pathwell.GenerateKeyPairWithAlgorithm(pathwell.Ed25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, prefix := range []string{"/pathwell?x=1", "/pathwell#top"} {
+		_, err := pathwell.NewClient(pathwell.ClientOptions{AgentID: "agent-123", PrivateKeyPEM: keyPair.PrivateKey, PathPrefix: prefix})
+		if err == nil {
+			t.Errorf("PathPrefix %q accepted", prefix)
+		}
+	}
+}
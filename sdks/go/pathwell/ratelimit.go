@@ -0,0 +1,86 @@
+package pathwell
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// rateLimiter is a token bucket holding up to burst tokens, refilled at
+// rate tokens per second. Each request attempt takes one token, waiting for
+// it when the bucket is empty.
+type rateLimiter struct {
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter returns nil, a disabled limiter, when RateLimit is zero
+func newRateLimiter(options ClientOptions) *rateLimiter {
+	if options.RateLimit <= 0 {
+		return nil
+	}
+	burst := options.RateLimitBurst
+	if burst <= 0 {
+		burst = 1
+	}
+	return &rateLimiter{
+		rate:   options.RateLimit,
+		burst:  float64(burst),
+		tokens: float64(burst),
+	}
+}
+
+// wait blocks until a token is available or ctx is done. A wait that would
+// outlast ctx's deadline fails at once instead of sleeping into it.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	delay, err := l.reserve(ctx, time.Now())
+	if err != nil || delay == 0 {
+		return err
+	}
+	if err := sleepContext(ctx, delay); err != nil {
+		l.cancel()
+		return fmt.Errorf("rate limit wait: %w", err)
+	}
+	return nil
+}
+
+// reserve takes a token at now and returns how long to wait before using
+// it. Tokens may go negative, which queues waiters in arrival order.
+func (l *rateLimiter) reserve(ctx context.Context, now time.Time) (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+
+	var delay time.Duration
+	if l.tokens < 1 {
+		delay = time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+	}
+	if deadline, ok := ctx.Deadline(); ok && delay > 0 && now.Add(delay).After(deadline) {
+		return 0, fmt.Errorf("rate limit wait of %s would exceed the context deadline", delay)
+	}
+	l.tokens--
+	return delay, nil
+}
+
+// cancel returns the token of a reservation that was not used
+func (l *rateLimiter) cancel() {
+	l.mu.Lock()
+	l.tokens++
+	l.mu.Unlock()
+}
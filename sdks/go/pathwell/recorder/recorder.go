@@ -0,0 +1,325 @@
+// Package recorder captures HTTP interactions to a golden file and replays
+// them, so code built on pathwell.Client can be tested without a network.
+//
+// Record once against a real proxy by wrapping the transport of the
+// HTTPClient passed to pathwell.NewClient, then Save the interactions:
+//
+//	rec := recorder.NewRecordingTransport(nil)
+//	client, err := pathwell.NewClient(pathwell.ClientOptions{
+//		AgentID:        "agent-123",
+//		PrivateKeyPath: "./agent.key",
+//		HTTPClient:     &http.Client{Transport: rec},
+//	})
+//	// ... make calls ...
+//	err = rec.Save("testdata/chat.json")
+//
+// In CI, replay them instead:
+//
+//	replay, err := recorder.LoadReplayTransport("testdata/chat.json")
+//	client, err := pathwell.NewClient(pathwell.ClientOptions{
+//		AgentID:       "agent-123",
+//		PrivateKeyPEM: testKey,
+//		HTTPClient:    &http.Client{Transport: replay},
+//	})
+//
+// Requests are matched on method, path with query, and body, so a recording
+// replays against any proxy URL. Request headers are neither matched nor
+// recorded, since the signing headers change on every request and must not
+// end up in a golden file. For the same reason the values of response
+// headers that carry credentials, such as Set-Cookie, are recorded as
+// "[REDACTED]". The random boundary of a multipart body is replaced with a
+// fixed one before recording and matching, so multipart uploads replay.
+package recorder
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// redactedHeaders are the response headers whose values are not recorded
+var redactedHeaders = []string{"Set-Cookie", "Authorization", "Proxy-Authorization"}
+
+// redactedValue replaces the value of a redacted header
+const redactedValue = "[REDACTED]"
+
+// recordedBoundary replaces multipart boundaries in recorded request bodies
+const recordedBoundary = "pathwell-recorder-boundary"
+
+// Interaction is one recorded request and its response
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is the part of a request used for matching
+type RecordedRequest struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Body   Body   `json:"body,omitempty"`
+}
+
+// RecordedResponse is a response as it is replayed
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       Body        `json:"body,omitempty"`
+}
+
+// Body is a message body. It is stored as a plain string when it is valid
+// UTF-8, which keeps golden files readable, and as base64 otherwise.
+type Body []byte
+
+// MarshalJSON encodes the body as a string or as {"base64": "..."}
+func (b Body) MarshalJSON() ([]byte, error) {
+	if utf8.Valid(b) {
+		return json.Marshal(string(b))
+	}
+	return json.Marshal(struct {
+		Base64 string `json:"base64"`
+	}{base64.StdEncoding.EncodeToString(b)})
+}
+
+// UnmarshalJSON decodes a body written by MarshalJSON
+func (b *Body) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*b = Body(s)
+		return nil
+	}
+
+	var encoded struct {
+		Base64 string `json:"base64"`
+	}
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return fmt.Errorf("invalid recorded body: %w", err)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(encoded.Base64)
+	if err != nil {
+		return fmt.Errorf("invalid recorded body: %w", err)
+	}
+	*b = decoded
+	return nil
+}
+
+// RecordingTransport forwards requests to another transport and records
+// every interaction. It is safe for concurrent use.
+type RecordingTransport struct {
+	transport http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []Interaction
+}
+
+// NewRecordingTransport records the requests sent through transport.
+// A nil transport means http.DefaultTransport.
+func NewRecordingTransport(transport http.RoundTripper) *RecordingTransport {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &RecordingTransport{transport: transport}
+}
+
+// RoundTrip sends req and records it with its response. The response body
+// is read in full and replaced with an in-memory copy; the caller sees the
+// response headers unredacted.
+func (t *RecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to record response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	t.mu.Lock()
+	t.interactions = append(t.interactions, Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			Path:   req.URL.RequestURI(),
+			Body:   normalizeBoundary(req, reqBody),
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     redactHeaders(resp.Header),
+			Body:       respBody,
+		},
+	})
+	t.mu.Unlock()
+
+	return resp, nil
+}
+
+// Interactions returns a copy of the interactions recorded so far
+func (t *RecordingTransport) Interactions() []Interaction {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Interaction(nil), t.interactions...)
+}
+
+// Save writes the recorded interactions to path as indented JSON
+func (t *RecordingTransport) Save(path string) error {
+	data, err := json.MarshalIndent(t.Interactions(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode interactions: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write interactions: %w", err)
+	}
+	return nil
+}
+
+// ReplayTransport answers requests from recorded interactions without
+// touching the network. Each interaction is replayed once, in recorded
+// order among interactions that match the same request. It is safe for
+// concurrent use.
+type ReplayTransport struct {
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewReplayTransport replays the given interactions
+func NewReplayTransport(interactions []Interaction) *ReplayTransport {
+	return &ReplayTransport{
+		interactions: interactions,
+		used:         make([]bool, len(interactions)),
+	}
+}
+
+// LoadReplayTransport replays the interactions in a file written by
+// RecordingTransport.Save
+func LoadReplayTransport(path string) (*ReplayTransport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read interactions: %w", err)
+	}
+
+	var interactions []Interaction
+	if err := json.Unmarshal(data, &interactions); err != nil {
+		return nil, fmt.Errorf("failed to decode interactions: %w", err)
+	}
+	return NewReplayTransport(interactions), nil
+}
+
+// RoundTrip returns the first unused interaction matching req's method, path
+// and body, or an error when none is left
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	body = normalizeBoundary(req, body)
+	path := req.URL.RequestURI()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i, interaction := range t.interactions {
+		recorded := interaction.Request
+		if t.used[i] || recorded.Method != req.Method || recorded.Path != path || !bytes.Equal(recorded.Body, body) {
+			continue
+		}
+		t.used[i] = true
+
+		header := interaction.Response.Header.Clone()
+		if header == nil {
+			header = make(http.Header)
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+			StatusCode:    interaction.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewReader(interaction.Response.Body)),
+			ContentLength: int64(len(interaction.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("no recorded interaction for %s %s", req.Method, path)
+}
+
+// Unused returns the interactions that were never replayed, so a test can
+// check that every recorded call was made
+func (t *ReplayTransport) Unused() []Interaction {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var unused []Interaction
+	for i, interaction := range t.interactions {
+		if !t.used[i] {
+			unused = append(unused, interaction)
+		}
+	}
+	return unused
+}
+
+// readRequestBody returns req's body. It reads a fresh copy from GetBody when
+// it can, and otherwise replaces the body with an in-memory copy.
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	if req.GetBody != nil {
+		rc, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+		defer rc.Close()
+		body, err := io.ReadAll(rc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+		return body, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// redactHeaders returns a copy of header with the values of redactedHeaders
+// replaced
+func redactHeaders(header http.Header) http.Header {
+	redacted := header.Clone()
+	for _, name := range redactedHeaders {
+		if len(redacted.Values(name)) > 0 {
+			redacted.Set(name, redactedValue)
+		}
+	}
+	return redacted
+}
+
+// normalizeBoundary replaces the boundary of a multipart request body with
+// recordedBoundary, since multipart writers pick a random one per request
+func normalizeBoundary(req *http.Request, body []byte) []byte {
+	mediaType, params, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil || !strings.HasPrefix(mediaType, "multipart/") || params["boundary"] == "" {
+		return body
+	}
+	return bytes.ReplaceAll(body, []byte(params["boundary"]), []byte(recordedBoundary))
+}
@@ -0,0 +1,116 @@
+package recorder_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pathwell/connect-go/pathwell"
+	"github.com/pathwell/connect-go/pathwell/recorder"
+)
+
+// newClient returns a client sending through transport
+func newClient(t *testing.T, proxyURL string, transport http.RoundTripper) *pathwell.Client {
+	t.Helper()
+	keyPair, err := pathwell.GenerateKeyPairWithAlgorithm(pathwell.Ed25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := pathwell.NewClient(pathwell.ClientOptions{
+		AgentID:       "agent-123",
+		PrivateKeyPEM: keyPair.PrivateKey,
+		ProxyURL:      proxyURL,
+		HTTPClient:    &http.Client{Transport: transport},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func upload() *pathwell.MultipartBody {
+	return &pathwell.MultipartBody{
+		Fields: map[string]string{"title": "report", "owner": "ops", "year": "2026"},
+		Files:  []pathwell.MultipartFile{{FieldName: "file", FileName: "report.csv", Content: strings.NewReader("a,b\n1,2\n")}},
+	}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil || r.FormValue("title") != "report" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "secret-session"})
+		w.Header().Set("Authorization", "Bearer secret-token")
+		io.WriteString(w, `{"id":"upload-1"}`)
+	}))
+	defer server.Close()
+
+	rec := recorder.NewRecordingTransport(nil)
+	client := newClient(t, server.URL, rec)
+	resp, err := client.Post("https://api.example.com/uploads", nil, upload())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status %d", resp.StatusCode)
+	}
+	// The caller still sees the real headers
+	if len(resp.Cookies()) != 1 || resp.Cookies()[0].Value != "secret-session" {
+		t.Fatalf("cookies = %v", resp.Cookies())
+	}
+
+	path := filepath.Join(t.TempDir(), "uploads.json")
+	if err := rec.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden := string(data)
+	for _, secret := range []string{"secret-session", "secret-token"} {
+		if strings.Contains(golden, secret) {
+			t.Errorf("golden file contains %q:\n%s", secret, golden)
+		}
+	}
+	if !strings.Contains(golden, "pathwell-recorder-boundary") {
+		t.Errorf("golden file keeps the random boundary:\n%s", golden)
+	}
+
+	// A new upload picks a new boundary and still matches the recording
+	replay, err := recorder.LoadReplayTransport(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client = newClient(t, "http://replay.invalid", replay)
+	resp, err = client.Post("https://api.example.com/uploads", nil, upload())
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != `{"id":"upload-1"}` {
+		t.Fatalf("replayed body %q", body)
+	}
+	if got := resp.Header.Get("Set-Cookie"); got != "[REDACTED]" {
+		t.Errorf("replayed Set-Cookie = %q", got)
+	}
+	if len(replay.Unused()) != 0 {
+		t.Errorf("unused interactions: %v", replay.Unused())
+	}
+}
+
+func TestReplayUnmatched(t *testing.T) {
+	replay := recorder.NewReplayTransport(nil)
+	client := newClient(t, "http://replay.invalid", replay)
+	if _, err := client.Get("https://api.example.com/x", nil); err == nil || !strings.Contains(err.Error(), "no recorded interaction") {
+		t.Fatalf("error = %v", err)
+	}
+}
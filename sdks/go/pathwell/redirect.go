@@ -0,0 +1,141 @@
+package pathwell
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxRedirects is how many redirects are followed before giving up
+const maxRedirects = 10
+
+// RedirectPolicy controls how the client handles 3xx responses from the proxy
+type RedirectPolicy int
+
+const (
+	// SafeRedirects, the default, follows redirects that stay on the proxy,
+	// re-signing each one for its new path. Redirects elsewhere are returned
+	// as-is.
+	SafeRedirects RedirectPolicy = iota
+	// NoRedirects returns 3xx responses to the caller as-is
+	NoRedirects
+	// DefaultRedirects leaves redirects to the http.Client, as net/http does
+	// by default: it follows up to 10 of them without re-signing, so the
+	// proxy will usually reject any hop whose path changed
+	DefaultRedirects
+)
+
+// String returns the name of the policy
+func (p RedirectPolicy) String() string {
+	switch p {
+	case SafeRedirects:
+		return "SafeRedirects"
+	case NoRedirects:
+		return "NoRedirects"
+	case DefaultRedirects:
+		return "DefaultRedirects"
+	default:
+		return fmt.Sprintf("RedirectPolicy(%d)", int(p))
+	}
+}
+
+// withRedirectPolicy returns a copy of httpClient whose CheckRedirect applies
+// policy. Under DefaultRedirects the client still follows redirects, but
+// removes the headers matched by strip, the signing headers and credentials,
+// from any hop that leaves the proxy's origin so they never leak to another
+// host.
+func withRedirectPolicy(httpClient *http.Client, policy RedirectPolicy, proxy *url.URL, strip func(name string) bool) *http.Client {
+	wrapped := *httpClient
+	if policy != DefaultRedirects {
+		wrapped.CheckRedirect = func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+		return &wrapped
+	}
+
+	next := httpClient.CheckRedirect
+	wrapped.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if !sameOrigin(req.URL, proxy) {
+			for name := range req.Header {
+				if strip(name) {
+					req.Header.Del(name)
+				}
+			}
+		}
+		if next != nil {
+			return next(req, via)
+		}
+		if len(via) >= maxRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxRedirects)
+		}
+		return nil
+	}
+	return &wrapped
+}
+
+// sameOrigin reports whether a and b share a scheme and host
+func sameOrigin(a, b *url.URL) bool {
+	return strings.EqualFold(a.Scheme, b.Scheme) && strings.EqualFold(a.Host, b.Host)
+}
+
+// isRedirect reports whether code is a redirect that carries a Location
+func isRedirect(code int) bool {
+	switch code {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+// redirectRequest builds the request that follows resp, or reports false when
+// the Location leaves the proxy. 301, 302 and 303 turn into a bodiless GET as
+// in net/http; 307 and 308 resend the same method and body. Streamed bodies
+// cannot be resent, so their redirects are never followed.
+func (c *Client) redirectRequest(prepared *preparedRequest, resp *http.Response) (*preparedRequest, bool) {
+	if prepared.stream != nil {
+		return nil, false
+	}
+	location, err := resp.Location()
+	if err != nil {
+		return nil, false
+	}
+	proxy, err := url.Parse(c.proxyURL)
+	if err != nil || !sameOrigin(location, proxy) {
+		return nil, false
+	}
+
+	// Only locations under the proxy URL's own path map back to a request path
+	path := location.EscapedPath()
+	prefix := proxy.EscapedPath()
+	if prefix != "" {
+		if path != prefix && !strings.HasPrefix(path, prefix+"/") {
+			return nil, false
+		}
+		path = strings.TrimPrefix(path, prefix)
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	if location.RawQuery != "" {
+		path += "?" + escapeRawQuery(location.RawQuery)
+	}
+
+	next := *prepared
+	next.path = path
+	if resp.StatusCode != http.StatusTemporaryRedirect && resp.StatusCode != http.StatusPermanentRedirect &&
+		prepared.method != http.MethodGet && prepared.method != http.MethodHead {
+		next.method = http.MethodGet
+		next.body = nil
+		next.headers = make(map[string]string, len(prepared.headers))
+		for k, v := range prepared.headers {
+			switch http.CanonicalHeaderKey(k) {
+			case "Content-Type", "Content-Encoding", "Content-Length":
+			default:
+				next.headers[k] = v
+			}
+		}
+	}
+	return &next, true
+}
@@ -0,0 +1,207 @@
+package pathwell_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pathwell/connect-go/pathwell"
+	"github.com/pathwell/connect-go/pathwell/pathwelltest"
+)
+
+// movedHandler redirects /old to /new with status and echoes the body that
+// reaches /new
+func movedHandler(status int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/new", status)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		w.Write(body)
+	})
+}
+
+func TestSafeRedirectsIsDefault(t *testing.T) {
+	var policy pathwell.RedirectPolicy
+	if policy != pathwell.SafeRedirects {
+		t.Fatalf("zero RedirectPolicy is %s, want SafeRedirects", policy)
+	}
+
+	// The verifying proxy checks every hop, so the 307 must be re-signed
+	// for /new to get through
+	proxy, client := newProxyClient(t, pathwelltest.ProxyOptions{Handler: movedHandler(http.StatusTemporaryRedirect)},
+		pathwell.ClientOptions{TargetURL: "https://api.example.com"})
+
+	resp, err := client.Post("/old", map[string]string{"Content-Type": "text/plain"}, "payload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != "payload" {
+		t.Fatalf("status %d, body %q", resp.StatusCode, body)
+	}
+	requests := proxy.Requests()
+	if len(requests) != 2 || requests[1].Path != "/new" || requests[1].Method != "POST" {
+		t.Fatalf("hops = %+v", requests)
+	}
+}
+
+func TestDefaultRedirectsDoesNotResign(t *testing.T) {
+	_, client := newProxyClient(t, pathwelltest.ProxyOptions{Handler: movedHandler(http.StatusTemporaryRedirect)},
+		pathwell.ClientOptions{TargetURL: "https://api.example.com", RedirectPolicy: pathwell.DefaultRedirects})
+
+	resp, err := client.Post("/old", nil, "payload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("status %d, want 401 for a hop replayed with the old signature", resp.StatusCode)
+	}
+}
+
+func TestNoRedirects(t *testing.T) {
+	proxy, client := newProxyClient(t, pathwelltest.ProxyOptions{Handler: movedHandler(http.StatusFound)},
+		pathwell.ClientOptions{TargetURL: "https://api.example.com", RedirectPolicy: pathwell.NoRedirects})
+
+	resp, err := client.Get("/old", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound || len(proxy.Requests()) != 1 {
+		t.Fatalf("status %d after %d requests, want the 302 itself", resp.StatusCode, len(proxy.Requests()))
+	}
+}
+
+func TestRedirectReplaysBody(t *testing.T) {
+	for _, policy := range []pathwell.RedirectPolicy{pathwell.SafeRedirects, pathwell.DefaultRedirects} {
+		for _, status := range []int{http.StatusTemporaryRedirect, http.StatusPermanentRedirect} {
+			var got []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				got = append(got, r.Method+" "+r.URL.Path+" "+string(body))
+				if r.URL.Path == "/old" {
+					http.Redirect(w, r, "/new", status)
+				}
+			}))
+
+			client := newStubClient(t, server.URL, pathwell.ClientOptions{RedirectPolicy: policy})
+			resp, err := client.Post("https://api.example.com/old", nil, map[string]string{"order": "42"})
+			server.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			want := []string{`POST /old {"order":"42"}`, `POST /new {"order":"42"}`}
+			if len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+				t.Errorf("%s, %d: hops = %q, want %q", policy, status, got, want)
+			}
+		}
+	}
+}
+
+func TestRetryReplaysBody(t *testing.T) {
+	var got []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got = append(got, string(body))
+		if len(got) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	client := newStubClient(t, server.URL, pathwell.ClientOptions{
+		MaxRetries:     1,
+		RetryBaseDelay: time.Millisecond,
+	})
+	resp, err := client.Put("https://api.example.com/items/1", nil, "payload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if len(got) != 2 || got[0] != "payload" || got[1] != "payload" {
+		t.Fatalf("attempt bodies = %q", got)
+	}
+}
+
+func TestCrossOriginRedirectStripsSigningHeaders(t *testing.T) {
+	var leaked http.Header
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		leaked = r.Header.Clone()
+	}))
+	defer other.Close()
+	var signed http.Header
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signed = r.Header.Clone()
+		http.Redirect(w, r, other.URL+"/elsewhere", http.StatusFound)
+	}))
+	defer proxy.Close()
+
+	client := newStubClient(t, proxy.URL, pathwell.ClientOptions{
+		RedirectPolicy:  pathwell.DefaultRedirects,
+		KeyID:           "key-1",
+		BearerToken:     "upstream-token",
+		AgentIDHeader:   "X-Edge-Agent",
+		IdempotencyKeys: true,
+	})
+	resp, err := client.Post("https://api.example.com/x", nil, "payload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if leaked == nil {
+		t.Fatal("the redirect was not followed")
+	}
+
+	for _, name := range []string{
+		"X-Edge-Agent",
+		pathwell.DefaultSignatureHeader,
+		pathwell.DefaultTimestampHeader,
+		pathwell.DefaultNonceHeader,
+		pathwell.DefaultKeyIDHeader,
+		pathwell.DefaultTargetHeader,
+		"Authorization",
+	} {
+		if signed.Get(name) == "" {
+			t.Errorf("%s was not sent to the proxy", name)
+		}
+		if v := leaked.Get(name); v != "" {
+			t.Errorf("%s leaked to another origin: %q", name, v)
+		}
+	}
+	for name := range leaked {
+		if strings.HasPrefix(name, "X-Pathwell-") {
+			t.Errorf("%s leaked to another origin", name)
+		}
+	}
+}
+
+func TestSafeRedirectsDoesNotLeaveProxy(t *testing.T) {
+	var followed bool
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		followed = true
+	}))
+	defer other.Close()
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, other.URL+"/elsewhere", http.StatusTemporaryRedirect)
+	}))
+	defer proxy.Close()
+
+	client := newStubClient(t, proxy.URL, pathwell.ClientOptions{})
+	resp, err := client.Get("https://api.example.com/x", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if followed || resp.StatusCode != http.StatusTemporaryRedirect {
+		t.Fatalf("followed = %v, status %d; want the 307 returned as-is", followed, resp.StatusCode)
+	}
+}
@@ -0,0 +1,66 @@
+package pathwell
+
+import (
+	"crypto"
+	"fmt"
+)
+
+// ReloadKey parses privateKeyPEM and makes it the client's signing key, so a
+// long-running agent can pick up a rotated key without being rebuilt. keyID
+// replaces KeyID, so requests signed with the new key advertise it; pass ""
+// to send none. Calls in flight finish with the key they started signing
+// with, and every later attempt, including retries, uses the new one.
+//
+// The KeyNotBefore/KeyNotAfter window described the old key and is cleared.
+// Encrypted keys are parsed with ParsePrivateKeyWithPassphrase and passed
+// to ReloadSigner instead.
+func (c *Client) ReloadKey(privateKeyPEM string, keyID string) error {
+	signer, err := ParsePrivateKey(privateKeyPEM)
+	if err != nil {
+		return fmt.Errorf("invalid private key: %w", err)
+	}
+	return c.ReloadSigner(signer, keyID)
+}
+
+// ReloadKeyFile is ReloadKey with the key read from a file
+func (c *Client) ReloadKeyFile(keyPath string, keyID string) error {
+	privateKeyPEM, err := LoadPrivateKey(keyPath)
+	if err != nil {
+		return fmt.Errorf("failed to load private key: %w", err)
+	}
+	return c.ReloadKey(privateKeyPEM, keyID)
+}
+
+// ReloadSigner is ReloadKey for an already parsed key or a KMS-backed
+// signer. The old key stays in place if signer is rejected.
+func (c *Client) ReloadSigner(signer crypto.Signer, keyID string) error {
+	if signer == nil {
+		return fmt.Errorf("invalid signer: nil")
+	}
+	if err := checkPublicKeyType(signer.Public()); err != nil {
+		return fmt.Errorf("invalid signer: %w", err)
+	}
+	if err := validateKeyID(keyID); err != nil {
+		return err
+	}
+
+	c.keyMu.Lock()
+	c.key = signingKey{signer: signer, keyID: keyID}
+	c.keyMu.Unlock()
+	return nil
+}
+
+// signingKey returns the current signing key
+func (c *Client) signingKey() signingKey {
+	c.keyMu.RLock()
+	defer c.keyMu.RUnlock()
+	return c.key
+}
+
+// PublicKeyPEM returns the PEM (PKIX) public key of the client's current
+// signing key, derived from the loaded key without reading any files. Use
+// it for self-registration, or to check the key matches the one the proxy
+// has registered. It follows ReloadKey.
+func (c *Client) PublicKeyPEM() (string, error) {
+	return encodePublicKey(c.signingKey().signer.Public())
+}
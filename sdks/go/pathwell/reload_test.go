@@ -0,0 +1,152 @@
+package pathwell_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/pathwell/connect-go/pathwell"
+)
+
+// TestReloadKeyDuringCalls swaps between two keys while calls are in
+// flight. Every request must verify against the public key named by the
+// key ID it carries, so a signature made with one key and sent with the
+// other's ID fails.
+func TestReloadKeyDuringCalls(t *testing.T) {
+	keys := make(map[string]*pathwell.KeyPair)
+	for _, id := range []string{"old", "new"} {
+		keyPair, err := pathwell.GenerateKeyPairWithAlgorithm(pathwell.Ed25519)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[id] = keyPair
+	}
+
+	var mu sync.Mutex
+	seen := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		keyID := r.Header.Get(pathwell.DefaultKeyIDHeader)
+		keyPair, ok := keys[keyID]
+		if !ok {
+			http.Error(w, "unknown key id "+keyID, http.StatusUnauthorized)
+			return
+		}
+		err := pathwell.SignatureInput{
+			Method:    r.Method,
+			Path:      r.URL.RequestURI(),
+			Body:      body,
+			Timestamp: r.Header.Get(pathwell.DefaultTimestampHeader),
+			Nonce:     r.Header.Get(pathwell.DefaultNonceHeader),
+			KeyID:     keyID,
+		}.Verify(keyPair.PublicKey, r.Header.Get(pathwell.DefaultSignatureHeader))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		mu.Lock()
+		seen[keyID]++
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	client, err := pathwell.NewClient(pathwell.ClientOptions{
+		AgentID:       "agent-123",
+		PrivateKeyPEM: keys["old"].PrivateKey,
+		KeyID:         "old",
+		ProxyURL:      server.URL,
+		TargetURL:     "https://api.example.com",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const goroutines, callsEach = 16, 25
+	var wg sync.WaitGroup
+	errs := make(chan error, goroutines*callsEach+1)
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < callsEach; i++ {
+				resp, err := client.Call("POST", "/items", nil, map[string]int{"i": i})
+				if err != nil {
+					errs <- err
+					continue
+				}
+				if resp.StatusCode != http.StatusOK {
+					msg, _ := io.ReadAll(resp.Body)
+					t.Errorf("status %d: %s", resp.StatusCode, msg)
+				}
+				resp.Body.Close()
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 200; i++ {
+			id := []string{"new", "old"}[i%2]
+			if err := client.ReloadKey(keys[id].PrivateKey, id); err != nil {
+				errs <- err
+				return
+			}
+		}
+	}()
+	wg.Wait()
+	<-done
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	// After the last reload, new requests use the new key and advertise it
+	if err := client.ReloadKey(keys["new"].PrivateKey, "new"); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	before := seen["new"]
+	mu.Unlock()
+	resp, err := client.Get("/items", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	mu.Lock()
+	after := seen["new"]
+	mu.Unlock()
+	if resp.StatusCode != http.StatusOK || after != before+1 {
+		t.Fatalf("request after reload: status %d, new-key requests %d -> %d", resp.StatusCode, before, after)
+	}
+	publicKey, err := client.PublicKeyPEM()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := pathwell.KeyPairMatches(keys["new"].PrivateKey, publicKey); err != nil || !ok {
+		t.Fatalf("PublicKeyPEM does not follow ReloadKey: %v", err)
+	}
+}
+
+func TestReloadKeyRejectsInvalidKey(t *testing.T) {
+	keyPair, err := pathwell.GenerateKeyPairWithAlgorithm(pathwell.Ed25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := pathwell.NewClient(pathwell.ClientOptions{AgentID: "agent-123", PrivateKeyPEM: keyPair.PrivateKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.ReloadKey("not a key", "k2"); err == nil {
+		t.Fatal("expected an error for an invalid key")
+	}
+	publicKey, err := client.PublicKeyPEM()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := pathwell.KeyPairMatches(keyPair.PrivateKey, publicKey); !ok {
+		t.Fatal("a rejected reload replaced the key")
+	}
+}
@@ -0,0 +1,41 @@
+package pathwell
+
+import (
+	"io"
+	"net/http"
+)
+
+// maxDrainSize bounds how much of an unwanted response body is read before
+// closing it. Draining lets the connection be reused for the next request;
+// past this size, reading on costs more than opening a new connection.
+const maxDrainSize = 256 << 10
+
+// requestIDHeaders are checked in order for the ID the proxy assigned to a request
+var requestIDHeaders = []string{
+	"X-Pathwell-Request-ID",
+	"X-Request-ID",
+	"X-Pathwell-Trace-ID",
+}
+
+// RequestID returns the ID the proxy assigned to the request, taken from
+// X-Pathwell-Request-ID, X-Request-ID or X-Pathwell-Trace-ID, in that order.
+// Include it when reporting a failed call.
+func RequestID(resp *http.Response) string {
+	if resp == nil {
+		return ""
+	}
+	for _, name := range requestIDHeaders {
+		if id := resp.Header.Get(name); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// discardBody drains up to maxDrainSize of a response body and closes it,
+// so its connection goes back to the pool even when the body is unwanted
+// or has no Content-Length
+func discardBody(resp *http.Response) {
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainSize))
+	resp.Body.Close()
+}
@@ -0,0 +1,226 @@
+package pathwell_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/pathwell/connect-go/pathwell"
+)
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    string
+	}{
+		{"none", nil, ""},
+		{"pathwell", map[string]string{"X-Pathwell-Request-ID": "pw-1"}, "pw-1"},
+		{"generic", map[string]string{"X-Request-Id": "req-1"}, "req-1"},
+		{"trace", map[string]string{"X-Pathwell-Trace-ID": "trace-1"}, "trace-1"},
+		{"pathwell first", map[string]string{"X-Request-ID": "req-1", "X-Pathwell-Request-ID": "pw-1", "X-Pathwell-Trace-ID": "trace-1"}, "pw-1"},
+		{"generic before trace", map[string]string{"X-Request-ID": "req-1", "X-Pathwell-Trace-ID": "trace-1"}, "req-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for name, value := range tt.headers {
+					w.Header().Set(name, value)
+				}
+			}))
+			defer server.Close()
+
+			client := newStubClient(t, server.URL, pathwell.ClientOptions{})
+			resp, err := client.Get("https://api.example.com/x", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if got := pathwell.RequestID(resp); got != tt.want {
+				t.Errorf("RequestID = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if got := pathwell.RequestID(nil); got != "" {
+		t.Errorf("RequestID(nil) = %q", got)
+	}
+}
+
+func TestAPIErrorRequestID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Pathwell-Request-ID", "pw-42")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"error":"agent not allowed","code":"forbidden"}`))
+	}))
+	defer server.Close()
+
+	client := newStubClient(t, server.URL, pathwell.ClientOptions{ErrorOnHTTPError: true})
+	_, err := client.Get("https://api.example.com/x", nil)
+	var apiErr *pathwell.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("error = %v, want *APIError", err)
+	}
+	if apiErr.RequestID != "pw-42" || apiErr.StatusCode != http.StatusForbidden {
+		t.Fatalf("APIError = %+v", apiErr)
+	}
+	if !strings.Contains(apiErr.Error(), "(request id pw-42)") {
+		t.Errorf("Error() = %q does not mention the request ID", apiErr.Error())
+	}
+}
+
+// oversizedHandler sends size bytes of JSON string, with a Content-Length
+// or, when chunked, without one
+func oversizedHandler(size int, chunked bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := `"` + strings.Repeat("a", size-2) + `"`
+		w.Header().Set("Content-Type", "application/json")
+		if !chunked {
+			w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+			io.WriteString(w, body)
+			return
+		}
+		for len(body) > 0 {
+			n := min(len(body), 4096)
+			io.WriteString(w, body[:n])
+			w.(http.Flusher).Flush()
+			body = body[n:]
+		}
+	})
+}
+
+func TestMaxResponseBytes(t *testing.T) {
+	const limit = 1024
+	for _, chunked := range []bool{false, true} {
+		server := httptest.NewServer(oversizedHandler(1<<20, chunked))
+		client := newStubClient(t, server.URL, pathwell.ClientOptions{MaxResponseBytes: limit})
+
+		if _, _, err := client.GetBytes("https://api.example.com/big", nil); !errors.Is(err, pathwell.ErrResponseTooLarge) {
+			t.Errorf("chunked=%v: GetBytes error = %v, want ErrResponseTooLarge", chunked, err)
+		}
+		var out string
+		if err := client.CallJSON("GET", "https://api.example.com/big", nil, nil, &out); !errors.Is(err, pathwell.ErrResponseTooLarge) {
+			t.Errorf("chunked=%v: CallJSON error = %v, want ErrResponseTooLarge", chunked, err)
+		}
+
+		// CallStream hands the body over unread and is exempt
+		stream, err := client.CallStream(context.Background(), "GET", "https://api.example.com/big", nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n, err := io.Copy(io.Discard, stream.Body)
+		stream.Body.Close()
+		if err != nil || n != 1<<20 {
+			t.Errorf("chunked=%v: CallStream read %d bytes, %v", chunked, n, err)
+		}
+		server.Close()
+	}
+
+	server := httptest.NewServer(oversizedHandler(limit, true))
+	defer server.Close()
+	client := newStubClient(t, server.URL, pathwell.ClientOptions{MaxResponseBytes: limit})
+	data, _, err := client.GetBytes("https://api.example.com/fits", nil)
+	if err != nil || len(data) != limit {
+		t.Fatalf("body of exactly the limit: %d bytes, %v", len(data), err)
+	}
+}
+
+// Error bodies are capped whether or not MaxResponseBytes is set
+func TestAPIErrorBodyCapped(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		io.WriteString(w, strings.Repeat("x", 1<<20))
+	}))
+	defer server.Close()
+
+	client := newStubClient(t, server.URL, pathwell.ClientOptions{ErrorOnHTTPError: true})
+	_, err := client.Get("https://api.example.com/x", nil)
+	var apiErr *pathwell.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("error = %v, want *APIError", err)
+	}
+	if len(apiErr.Body) != 64<<10 {
+		t.Fatalf("APIError body is %d bytes, want 64 KiB", len(apiErr.Body))
+	}
+}
+
+// chunkedHandler answers with status and a body without Content-Length
+func chunkedHandler(status int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		io.WriteString(w, `{"error":"`)
+		w.(http.Flusher).Flush()
+		io.WriteString(w, strings.Repeat("x", 100<<10))
+		io.WriteString(w, `"}`)
+	})
+}
+
+// countingClient returns a stub client counting the connections it dials
+func countingClient(t *testing.T, proxyURL string, options pathwell.ClientOptions) (*pathwell.Client, *atomic.Int64) {
+	t.Helper()
+	var dials atomic.Int64
+	var dialer net.Dialer
+	options.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dials.Add(1)
+		return dialer.DialContext(ctx, network, addr)
+	}
+	return newStubClient(t, proxyURL, options), &dials
+}
+
+// The helpers drain bodies without a Content-Length before closing them, so
+// keep-alive connections are reused even for responses nobody reads
+func TestConnectionReuse(t *testing.T) {
+	const calls = 5
+	tests := []struct {
+		name   string
+		status int
+		call   func(client *pathwell.Client) error
+	}{
+		{"CallJSON error", http.StatusBadRequest, func(client *pathwell.Client) error {
+			return client.CallJSON("GET", "https://api.example.com/x", nil, nil, nil)
+		}},
+		{"GetBytes error", http.StatusInternalServerError, func(client *pathwell.Client) error {
+			_, _, err := client.GetBytes("https://api.example.com/x", nil)
+			return err
+		}},
+		{"CheckStatus", http.StatusNotFound, func(client *pathwell.Client) error {
+			resp, err := client.Get("https://api.example.com/x", nil)
+			if err != nil {
+				return err
+			}
+			return pathwell.CheckStatus(resp)
+		}},
+		{"caller drains", http.StatusOK, func(client *pathwell.Client) error {
+			resp, err := client.Get("https://api.example.com/x", nil)
+			if err != nil {
+				return err
+			}
+			io.Copy(io.Discard, resp.Body)
+			return resp.Body.Close()
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(chunkedHandler(tt.status))
+			defer server.Close()
+			client, dials := countingClient(t, server.URL, pathwell.ClientOptions{})
+
+			for i := 0; i < calls; i++ {
+				tt.call(client)
+			}
+			if n := dials.Load(); n != 1 {
+				t.Fatalf("%d dials for %d sequential calls, want 1", n, calls)
+			}
+		})
+	}
+
+}
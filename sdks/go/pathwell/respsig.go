@@ -0,0 +1,97 @@
+package pathwell
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Headers carrying the proxy's signature over a response
+const (
+	ResponseSignatureHeader = "X-Pathwell-Response-Signature"
+	ResponseTimestampHeader = "X-Pathwell-Response-Timestamp"
+)
+
+// defaultResponseMaxSkew is how far a response timestamp may be from the
+// local clock when ResponseMaxSkew is unset
+const defaultResponseMaxSkew = 5 * time.Minute
+
+// ErrInvalidResponseSignature is returned, wrapped, when VerifyResponses is
+// set and a response is unsigned, its signature does not match or its
+// timestamp is outside ResponseMaxSkew
+var ErrInvalidResponseSignature = errors.New("invalid response signature")
+
+// responsePayload builds the string the proxy signs for a response:
+//
+//	STATUS\nREQUEST_NONCE\nTIMESTAMP\nBODY_HASH
+//
+// REQUEST_NONCE is the X-Pathwell-Nonce of the request being answered, so
+// a signed response cannot be replayed onto another request, and STATUS is
+// the three-digit status code. BODY_HASH is the hex SHA-256 of the body
+// bytes on the wire, before any Content-Encoding is undone, or empty when
+// the body is empty, as in request signatures.
+func responsePayload(statusCode int, requestNonce string, body []byte, timestamp string) []byte {
+	return []byte(fmt.Sprintf("%d\n%s\n%s\n%s", statusCode, requestNonce, timestamp, hashBody(body)))
+}
+
+// SignResponse signs a response the way the proxy does, returning the
+// base64 value for X-Pathwell-Response-Signature. requestNonce is the
+// X-Pathwell-Nonce of the request being answered. It is meant for servers
+// and test doubles that speak the protocol.
+func SignResponse(signer crypto.Signer, statusCode int, requestNonce string, body []byte, timestamp string) (string, error) {
+	signature, err := signPayload(signer, responsePayload(statusCode, requestNonce, body, timestamp))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign response: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(signature), nil
+}
+
+// verifyResponse checks the proxy's signature over resp, the answer to
+// req, and that its timestamp is within responseMaxSkew. The body is read
+// in full to hash it and replaced with an in-memory copy.
+func (c *Client) verifyResponse(req *http.Request, resp *http.Response) error {
+	signature := resp.Header.Get(ResponseSignatureHeader)
+	timestamp := resp.Header.Get(ResponseTimestampHeader)
+	if signature == "" || timestamp == "" {
+		discardBody(resp)
+		return fmt.Errorf("%w: response is not signed", ErrInvalidResponseSignature)
+	}
+
+	body, err := readLimited(resp.Body, c.maxResponseBytes)
+	resp.Body.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidResponseSignature, err)
+	}
+	payload := responsePayload(resp.StatusCode, req.Header.Get(c.headerNames.nonce), body, timestamp)
+	if err := verifyPayload(c.proxyPublicKey, payload, sig); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidResponseSignature, err)
+	}
+
+	// Checked after the signature, so the timestamp is known to be the
+	// proxy's; the skew-corrected clock is the one requests are signed with
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: invalid timestamp %q", ErrInvalidResponseSignature, timestamp)
+	}
+	skew := c.signingTime().Sub(time.Unix(unix, 0))
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > c.responseMaxSkew {
+		return fmt.Errorf("%w: timestamp %s is outside the allowed skew of %s",
+			ErrInvalidResponseSignature, timestamp, c.responseMaxSkew)
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package pathwell_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/pathwell/connect-go/pathwell"
+)
+
+// signingProxy answers every request with body, signed over status, the
+// request nonce and the timestamp that sign returns
+type signingProxy struct {
+	status int
+	body   []byte
+	gzip   bool
+	// nonce and timestamp override what is signed when set
+	nonce     string
+	timestamp time.Time
+	// signedStatus overrides the status that is signed when set
+	signedStatus int
+}
+
+func (p *signingProxy) start(t *testing.T) (*httptest.Server, string) {
+	t.Helper()
+	keyPair, err := pathwell.GenerateKeyPairWithAlgorithm(pathwell.Ed25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := pathwell.ParsePrivateKey(keyPair.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := p.body
+		if p.gzip {
+			if r.Header.Get("Accept-Encoding") != "gzip" {
+				t.Errorf("Accept-Encoding = %q, want gzip", r.Header.Get("Accept-Encoding"))
+			}
+			var buf bytes.Buffer
+			zw := gzip.NewWriter(&buf)
+			zw.Write(body)
+			zw.Close()
+			body = buf.Bytes()
+			w.Header().Set("Content-Encoding", "gzip")
+		} else if got := r.Header.Get("Accept-Encoding"); got != "identity" {
+			t.Errorf("Accept-Encoding = %q, want identity", got)
+		}
+
+		nonce := r.Header.Get(pathwell.DefaultNonceHeader)
+		if p.nonce != "" {
+			nonce = p.nonce
+		}
+		timestamp := time.Now()
+		if !p.timestamp.IsZero() {
+			timestamp = p.timestamp
+		}
+		status := p.status
+		if p.signedStatus != 0 {
+			status = p.signedStatus
+		}
+		ts := strconv.FormatInt(timestamp.Unix(), 10)
+		signature, err := pathwell.SignResponse(signer, status, nonce, body, ts)
+		if err != nil {
+			t.Error(err)
+		}
+		w.Header().Set(pathwell.ResponseTimestampHeader, ts)
+		w.Header().Set(pathwell.ResponseSignatureHeader, signature)
+		w.WriteHeader(p.status)
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return server, keyPair.PublicKey
+}
+
+func verifyingClient(t *testing.T, proxyURL, proxyPublicKey string) *pathwell.Client {
+	t.Helper()
+	keyPair, err := pathwell.GenerateKeyPairWithAlgorithm(pathwell.Ed25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := pathwell.NewClient(pathwell.ClientOptions{
+		AgentID:           "agent-123",
+		PrivateKeyPEM:     keyPair.PrivateKey,
+		ProxyURL:          proxyURL,
+		TargetURL:         "https://api.example.com",
+		VerifyResponses:   true,
+		ProxyPublicKeyPEM: proxyPublicKey,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestVerifyResponses(t *testing.T) {
+	tests := []struct {
+		name    string
+		proxy   signingProxy
+		headers map[string]string
+		wantErr bool
+	}{
+		{name: "valid", proxy: signingProxy{status: 200, body: []byte(`{"ok":true}`)}},
+		{name: "empty body", proxy: signingProxy{status: 204}},
+		{name: "error status", proxy: signingProxy{status: 503, body: []byte("down")}},
+		{
+			name:    "gzip signed as sent",
+			proxy:   signingProxy{status: 200, body: []byte(`{"ok":true}`), gzip: true},
+			headers: map[string]string{"Accept-Encoding": "gzip"},
+		},
+		{
+			name:    "stale timestamp",
+			proxy:   signingProxy{status: 200, body: []byte("x"), timestamp: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)},
+			wantErr: true,
+		},
+		{
+			name:    "future timestamp",
+			proxy:   signingProxy{status: 200, body: []byte("x"), timestamp: time.Now().Add(time.Hour)},
+			wantErr: true,
+		},
+		{
+			name:    "replayed onto another request",
+			proxy:   signingProxy{status: 200, body: []byte("x"), nonce: "0123456789abcdef0123456789abcdef"},
+			wantErr: true,
+		},
+		{
+			name:    "status changed",
+			proxy:   signingProxy{status: 200, body: []byte("x"), signedStatus: 403},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, publicKey := tt.proxy.start(t)
+			client := verifyingClient(t, server.URL, publicKey)
+
+			resp, err := client.Get("/x", tt.headers)
+			if tt.wantErr {
+				if !errors.Is(err, pathwell.ErrInvalidResponseSignature) {
+					t.Fatalf("err = %v, want ErrInvalidResponseSignature", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.proxy.status {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.proxy.status)
+			}
+		})
+	}
+}
+
+func TestUnsignedResponseRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("unsigned"))
+	}))
+	defer server.Close()
+	keyPair, err := pathwell.GenerateKeyPairWithAlgorithm(pathwell.Ed25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := verifyingClient(t, server.URL, keyPair.PublicKey)
+
+	if _, err := client.Get("/x", nil); !errors.Is(err, pathwell.ErrInvalidResponseSignature) {
+		t.Fatalf("err = %v, want ErrInvalidResponseSignature", err)
+	}
+}
@@ -0,0 +1,59 @@
+package pathwell
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// CallResult is a response with the timing and retry details of the call
+// that produced it
+type CallResult struct {
+	Response *http.Response
+	// Elapsed is the time from the start of the call until the final
+	// response headers arrived, including retries and backoff
+	Elapsed time.Duration
+	// Attempts is the number of requests sent, 1 when nothing was retried
+	Attempts int
+	// TimestampUsed is the X-Pathwell-Timestamp of the final attempt
+	TimestampUsed string
+}
+
+// callStatsKey is the context key for the callStats of an in-flight call
+type callStatsKey struct{}
+
+// callStats collects per-call details as the call runs. A call's attempts
+// run one after another, so it needs no locking.
+type callStats struct {
+	attempts  int
+	timestamp string
+}
+
+// callStatsFromContext returns the stats collector in ctx, if any
+func callStatsFromContext(ctx context.Context) *callStats {
+	stats, _ := ctx.Value(callStatsKey{}).(*callStats)
+	return stats
+}
+
+// CallWithResult makes a call like CallContext and reports its elapsed time,
+// attempt count and signing timestamp. The result is returned even when the
+// call fails, with a nil Response.
+func (c *Client) CallWithResult(
+	ctx context.Context,
+	method string,
+	requestURL string,
+	headers map[string]string,
+	body interface{},
+) (*CallResult, error) {
+	stats := &callStats{}
+	ctx = context.WithValue(ctx, callStatsKey{}, stats)
+
+	start := time.Now()
+	resp, err := c.CallContext(ctx, method, requestURL, headers, body)
+	return &CallResult{
+		Response:      resp,
+		Elapsed:       time.Since(start),
+		Attempts:      stats.attempts,
+		TimestampUsed: stats.timestamp,
+	}, err
+}
@@ -0,0 +1,217 @@
+package pathwell
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultRetryBaseDelay = 100 * time.Millisecond
+	maxRetryDelay         = 30 * time.Second
+)
+
+var defaultRetryableStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// JitterStrategy selects how retry delays are randomized, following the
+// strategies in AWS's "Exponential Backoff and Jitter". With many agents
+// retrying at once, jitter spreads their retries out instead of having them
+// hit the proxy in waves.
+type JitterStrategy int
+
+const (
+	// JitterFull waits a random time between zero and the exponential
+	// delay. It spreads retries the most and is the default.
+	JitterFull JitterStrategy = iota
+	// JitterNone waits exactly the exponential delay
+	JitterNone
+	// JitterEqual waits half the exponential delay plus a random time up
+	// to the other half, so there is always some wait
+	JitterEqual
+	// JitterDecorrelated waits a random time between the base delay and
+	// three times the previous wait, growing without a fixed schedule
+	JitterDecorrelated
+)
+
+// String returns the name of the strategy
+func (j JitterStrategy) String() string {
+	switch j {
+	case JitterFull:
+		return "JitterFull"
+	case JitterNone:
+		return "JitterNone"
+	case JitterEqual:
+		return "JitterEqual"
+	case JitterDecorrelated:
+		return "JitterDecorrelated"
+	default:
+		return fmt.Sprintf("JitterStrategy(%d)", int(j))
+	}
+}
+
+// retryPolicy holds the resolved retry settings of a Client
+type retryPolicy struct {
+	maxRetries    int
+	baseDelay     time.Duration
+	jitter        JitterStrategy
+	statusCodes   map[int]bool
+	nonIdempotent bool
+	maxElapsed    time.Duration
+}
+
+// newRetryPolicy resolves the retry settings in options, applying defaults
+func newRetryPolicy(options ClientOptions) retryPolicy {
+	baseDelay := options.RetryBaseDelay
+	if baseDelay <= 0 {
+		baseDelay = defaultRetryBaseDelay
+	}
+
+	codes := options.RetryableStatusCodes
+	if len(codes) == 0 {
+		codes = defaultRetryableStatusCodes
+	}
+	statusCodes := make(map[int]bool, len(codes))
+	for _, code := range codes {
+		statusCodes[code] = true
+	}
+
+	maxRetries := options.MaxRetries
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+
+	return retryPolicy{
+		maxRetries:    maxRetries,
+		baseDelay:     baseDelay,
+		jitter:        options.Jitter,
+		statusCodes:   statusCodes,
+		nonIdempotent: options.RetryNonIdempotent,
+		maxElapsed:    options.MaxRetryElapsedTime,
+	}
+}
+
+// allowsMethod reports whether requests with the given method may be retried
+func (p retryPolicy) allowsMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions,
+		http.MethodPut, http.MethodDelete, http.MethodTrace:
+		return true
+	default:
+		return p.nonIdempotent
+	}
+}
+
+// shouldRetry reports whether an attempt that ended with resp/err is worth
+// retrying. Transport errors are retried unless the context is done; other
+// errors, such as signing failures or an open circuit breaker, would only
+// fail again.
+func (p retryPolicy) shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		return errors.Is(err, ErrTransport)
+	}
+	return p.statusCodes[resp.StatusCode]
+}
+
+// withinBudget reports whether a retry starting at next still fits in the
+// call's MaxRetryElapsedTime, measured from start, and before ctx's deadline
+func (p retryPolicy) withinBudget(ctx context.Context, start, next time.Time) bool {
+	if p.maxElapsed > 0 && next.Sub(start) > p.maxElapsed {
+		return false
+	}
+	if deadline, ok := ctx.Deadline(); ok && !next.Before(deadline) {
+		return false
+	}
+	return true
+}
+
+// backoff returns the delay before the next attempt, exponential in the
+// attempt number and randomized by the jitter strategy. previous is the
+// backoff returned for the attempt before, or zero for the first retry; only
+// JitterDecorrelated uses it. Delays never exceed maxRetryDelay.
+func (p retryPolicy) backoff(attempt int, previous time.Duration) time.Duration {
+	if p.jitter == JitterDecorrelated {
+		if previous < p.baseDelay {
+			previous = p.baseDelay
+		}
+		upper := 3 * previous
+		if upper <= 0 || upper > maxRetryDelay {
+			upper = maxRetryDelay
+		}
+		if upper <= p.baseDelay {
+			return upper
+		}
+		return p.baseDelay + randDuration(upper-p.baseDelay)
+	}
+
+	delay := p.baseDelay << (attempt - 1)
+	if delay <= 0 || delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	switch p.jitter {
+	case JitterNone:
+		return delay
+	case JitterEqual:
+		half := delay / 2
+		return half + randDuration(delay-half)
+	default:
+		return randDuration(delay)
+	}
+}
+
+// randDuration returns a random duration in [0, max]
+func randDuration(max time.Duration) time.Duration {
+	return time.Duration(rand.Int63n(int64(max) + 1))
+}
+
+// retryAfter parses the Retry-After header of resp, in either delay-seconds
+// or HTTP-date form, into the wait before the next attempt
+func retryAfter(resp *http.Response, now time.Time) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	value := strings.TrimSpace(resp.Header.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		wait := date.Sub(now)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+// sleepContext waits for d, returning early with ctx's error if it is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,70 @@
+package pathwell
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoffBounds(t *testing.T) {
+	const base = 100 * time.Millisecond
+	for _, jitter := range []JitterStrategy{JitterFull, JitterNone, JitterEqual} {
+		p := retryPolicy{baseDelay: base, jitter: jitter}
+		for attempt := 1; attempt <= 12; attempt++ {
+			ceiling := base << (attempt - 1)
+			if ceiling > maxRetryDelay {
+				ceiling = maxRetryDelay
+			}
+			floor := time.Duration(0)
+			switch jitter {
+			case JitterNone:
+				floor = ceiling
+			case JitterEqual:
+				floor = ceiling / 2
+			}
+			for i := 0; i < 200; i++ {
+				if d := p.backoff(attempt, 0); d < floor || d > ceiling {
+					t.Fatalf("%s attempt %d: backoff %s outside [%s, %s]", jitter, attempt, d, floor, ceiling)
+				}
+			}
+		}
+	}
+}
+
+func TestBackoffDecorrelatedBounds(t *testing.T) {
+	const base = 100 * time.Millisecond
+	p := retryPolicy{baseDelay: base, jitter: JitterDecorrelated}
+	previous := time.Duration(0)
+	for attempt := 1; attempt <= 50; attempt++ {
+		ceiling := 3 * previous
+		if ceiling < 3*base {
+			ceiling = 3 * base
+		}
+		if ceiling > maxRetryDelay {
+			ceiling = maxRetryDelay
+		}
+		d := p.backoff(attempt, previous)
+		if d < base || d > ceiling {
+			t.Fatalf("attempt %d after %s: backoff %s outside [%s, %s]", attempt, previous, d, base, ceiling)
+		}
+		previous = d
+	}
+}
+
+func TestBackoffFullJitterSpreads(t *testing.T) {
+	// Full jitter is the default and should not collapse onto the ceiling
+	p := retryPolicy{baseDelay: time.Second}
+	seen := make(map[time.Duration]bool)
+	for i := 0; i < 50; i++ {
+		seen[p.backoff(3, 0)] = true
+	}
+	if len(seen) < 10 {
+		t.Fatalf("full jitter produced only %d distinct delays in 50 draws", len(seen))
+	}
+}
+
+func TestBackoffLargeAttemptCapped(t *testing.T) {
+	p := retryPolicy{baseDelay: time.Second, jitter: JitterNone}
+	if d := p.backoff(80, 0); d != maxRetryDelay {
+		t.Fatalf("backoff after overflow = %s, want %s", d, maxRetryDelay)
+	}
+}
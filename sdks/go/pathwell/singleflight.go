@@ -0,0 +1,123 @@
+package pathwell
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// flightGroup lets concurrent identical requests share one call. The first
+// caller for a key makes the call; callers arriving while it is in flight
+// wait for its result instead of sending their own.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flight
+}
+
+// flight is a call in progress or completed, with its buffered response
+type flight struct {
+	done chan struct{}
+	resp *http.Response
+	body []byte
+	err  error
+}
+
+// newFlightGroup returns nil, disabling coalescing, unless CoalesceGETs is set
+func newFlightGroup(options ClientOptions) *flightGroup {
+	if !options.CoalesceGETs {
+		return nil
+	}
+	return &flightGroup{calls: make(map[string]*flight)}
+}
+
+// do returns the result of fn for key, calling it only if no call for key
+// is in flight. The response body is read, up to limit bytes, so each
+// caller can be given a copy. The shared call runs on a context detached
+// from every caller's cancellation, bounded by timeout instead, so one
+// caller giving up does not fail the others; each caller, the first one
+// included, stops waiting when its own ctx is done.
+func (g *flightGroup) do(
+	ctx context.Context,
+	key string,
+	timeout time.Duration,
+	fn func(ctx context.Context) (*http.Response, error),
+	limit int64,
+) (*http.Response, error) {
+	g.mu.Lock()
+	f, ok := g.calls[key]
+	if !ok {
+		f = &flight{done: make(chan struct{})}
+		g.calls[key] = f
+		go g.run(context.WithoutCancel(ctx), key, f, timeout, fn, limit)
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-f.done:
+		return f.result()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// run makes the shared call for f and releases its waiters
+func (g *flightGroup) run(
+	ctx context.Context,
+	key string,
+	f *flight,
+	timeout time.Duration,
+	fn func(ctx context.Context) (*http.Response, error),
+	limit int64,
+) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	f.resp, f.err = fn(ctx)
+	if f.err == nil {
+		f.body, f.err = readLimited(f.resp.Body, limit)
+		f.resp.Body.Close()
+	}
+
+	// Later callers start a new call rather than reuse a finished one
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+	close(f.done)
+}
+
+// result returns a copy of the flight's response with its own body and
+// header, so callers cannot interfere with each other
+func (f *flight) result() (*http.Response, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	resp := *f.resp
+	resp.Header = f.resp.Header.Clone()
+	resp.Body = io.NopCloser(bytes.NewReader(f.body))
+	return &resp, nil
+}
+
+// flightKey identifies requests that are interchangeable: the same method,
+// upstream, path and query, and per-call headers
+func (p *preparedRequest) flightKey() string {
+	names := make([]string, 0, len(p.headers))
+	for name := range p.headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var key strings.Builder
+	key.WriteString(p.method + "\n" + p.target + "\n" + p.path)
+	for _, name := range names {
+		key.WriteString("\n" + http.CanonicalHeaderKey(name) + ":" + p.headers[name])
+	}
+	return key.String()
+}
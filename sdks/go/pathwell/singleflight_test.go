@@ -0,0 +1,105 @@
+package pathwell_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pathwell/connect-go/pathwell"
+)
+
+// blockingServer answers every request with body once release is closed,
+// signalling arrived as each request comes in
+func blockingServer(t *testing.T, body string) (server *httptest.Server, arrived chan struct{}, release chan struct{}, calls *atomic.Int64) {
+	t.Helper()
+	arrived = make(chan struct{}, 16)
+	release = make(chan struct{})
+	calls = new(atomic.Int64)
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		arrived <- struct{}{}
+		<-release
+		io.WriteString(w, body)
+	}))
+	t.Cleanup(server.Close)
+	return server, arrived, release, calls
+}
+
+type result struct {
+	body string
+	err  error
+}
+
+// getAsync runs a GET on ctx in the background
+func getAsync(client *pathwell.Client, ctx context.Context) <-chan result {
+	done := make(chan result, 1)
+	go func() {
+		resp, err := client.GetContext(ctx, "https://api.example.com/config", nil)
+		if err != nil {
+			done <- result{err: err}
+			return
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		done <- result{string(body), err}
+	}()
+	return done
+}
+
+// A waiter keeps its response when the caller that started the shared call
+// gives up
+func TestCoalescedFirstCallerCancels(t *testing.T) {
+	server, arrived, release, calls := blockingServer(t, "config-v1")
+	client := newStubClient(t, server.URL, pathwell.ClientOptions{CoalesceGETs: true})
+
+	firstCtx, cancelFirst := context.WithCancel(context.Background())
+	first := getAsync(client, firstCtx)
+	<-arrived
+
+	// The shared call is in flight, so this caller joins it
+	waiter := getAsync(client, context.Background())
+	time.Sleep(50 * time.Millisecond)
+
+	cancelFirst()
+	if got := <-first; !errors.Is(got.err, context.Canceled) {
+		t.Fatalf("first caller: %+v, want context.Canceled", got)
+	}
+	close(release)
+
+	if got := <-waiter; got.err != nil || got.body != "config-v1" {
+		t.Fatalf("waiter: %+v, want the shared response", got)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("%d requests sent, want 1 shared", n)
+	}
+}
+
+// A waiter that gives up does not affect the caller that started the call
+func TestCoalescedWaiterCancels(t *testing.T) {
+	server, arrived, release, calls := blockingServer(t, "config-v1")
+	client := newStubClient(t, server.URL, pathwell.ClientOptions{CoalesceGETs: true})
+
+	first := getAsync(client, context.Background())
+	<-arrived
+
+	waiterCtx, cancelWaiter := context.WithCancel(context.Background())
+	waiter := getAsync(client, waiterCtx)
+	time.Sleep(50 * time.Millisecond)
+	cancelWaiter()
+	if got := <-waiter; !errors.Is(got.err, context.Canceled) {
+		t.Fatalf("waiter: %+v, want context.Canceled", got)
+	}
+	close(release)
+
+	if got := <-first; got.err != nil || got.body != "config-v1" {
+		t.Fatalf("first caller: %+v", got)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("%d requests sent, want 1", n)
+	}
+}
@@ -0,0 +1,48 @@
+package pathwell
+
+import (
+	"net/http"
+	"time"
+)
+
+// defaultClockSkewThreshold is the skew reported to OnClockSkew by default
+const defaultClockSkewThreshold = 30 * time.Second
+
+// observeServerDate compares the Date header of a response with the local
+// clock, reports skew beyond the threshold and, with AutoCorrectSkew,
+// records the offset applied to later signing timestamps
+func (c *Client) observeServerDate(resp *http.Response) {
+	if c.onClockSkew == nil && !c.autoCorrectSkew {
+		return
+	}
+
+	serverDate, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return
+	}
+
+	// Positive skew means the local clock is behind the server
+	skew := serverDate.Sub(c.now())
+	magnitude := skew
+	if magnitude < 0 {
+		magnitude = -magnitude
+	}
+
+	if c.onClockSkew != nil && magnitude > c.clockSkewThreshold {
+		c.onClockSkew(skew)
+	}
+
+	// Date has one-second resolution, so smaller differences are noise
+	if c.autoCorrectSkew {
+		if magnitude <= time.Second {
+			skew = 0
+		}
+		c.skewOffset.Store(int64(skew))
+	}
+}
+
+// signingTime returns the time used for X-Pathwell-Timestamp, corrected by
+// the skew observed from the server when AutoCorrectSkew is enabled
+func (c *Client) signingTime() time.Time {
+	return c.now().Add(time.Duration(c.skewOffset.Load()))
+}
@@ -0,0 +1,119 @@
+package pathwell
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// StreamResponse is a response whose body is read directly from the network
+type StreamResponse struct {
+	StatusCode int
+	Status     string
+	Header     http.Header
+	// RequestID is the ID the proxy assigned to the request, if any
+	RequestID string
+	// Body must be closed by the caller
+	Body io.ReadCloser
+}
+
+// CallStream makes an authenticated request and returns the response body
+// unbuffered, for large downloads. It makes a single attempt: retries and
+// ErrorOnHTTPError are bypassed, so callers should check StatusCode. The
+// client's default 30 second timeout does not apply either; bound the
+// download with ctx.
+func (c *Client) CallStream(
+	ctx context.Context,
+	method string,
+	requestURL string,
+	headers map[string]string,
+	body interface{},
+) (*StreamResponse, error) {
+	resp, err := c.traced(ctx, method, func(ctx context.Context) (*http.Response, error) {
+		prepared, err := c.prepareRequest(method, requestURL, headers, body)
+		if err != nil {
+			return nil, err
+		}
+		return c.send(ctx, prepared)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &StreamResponse{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Header:     resp.Header,
+		RequestID:  RequestID(resp),
+		Body:       resp.Body,
+	}, nil
+}
+
+// CallWithHashedBody makes an authenticated request whose body is streamed
+// from r instead of buffered, for large uploads. Because the signature covers
+// the body hash, the caller supplies bodyHash, the hex SHA-256 of everything
+// r will yield, or "" for an empty body (the digest of an empty body is
+// accepted too and signed as ""). contentLength is the body size, or
+// -1 if unknown, in which case the body is sent with chunked transfer
+// encoding; the hash is still checked by the proxy against the reassembled
+// body. A contentLength of 0 requires an empty hash and a positive one a
+// non-empty hash.
+//
+// r can only be read once, so the call makes a single attempt: retries and
+// redirects are not followed. ErrorOnHTTPError still applies, but the
+// client's default 30 second timeout does not; bound the upload with ctx.
+// When the hash is not known up front, pass r to Call instead, which reads
+// it into memory to hash it and always sends a Content-Length, never a
+// chunked body.
+func (c *Client) CallWithHashedBody(
+	ctx context.Context,
+	method string,
+	requestURL string,
+	headers map[string]string,
+	r io.Reader,
+	contentLength int64,
+	bodyHash string,
+) (*http.Response, error) {
+	if contentLength < -1 {
+		return nil, fmt.Errorf("invalid content length %d: must be -1 (unknown) or at least 0", contentLength)
+	}
+	if bodyHash != "" {
+		decoded, err := hex.DecodeString(bodyHash)
+		if err != nil || len(decoded) != sha256.Size {
+			return nil, fmt.Errorf("invalid body hash %q: must be a hex SHA-256", bodyHash)
+		}
+		bodyHash = normalizeBodyHash(strings.ToLower(bodyHash))
+	}
+	// A declared length and a hash that disagree on whether the body is
+	// empty can never verify, so fail before sending
+	if contentLength == 0 && bodyHash != "" {
+		return nil, fmt.Errorf("invalid body hash %q: content length 0 means an empty body", bodyHash)
+	}
+	if contentLength > 0 && bodyHash == "" {
+		return nil, fmt.Errorf("missing body hash for a %d byte body", contentLength)
+	}
+
+	return c.traced(ctx, method, func(ctx context.Context) (*http.Response, error) {
+		prepared, err := c.prepareRequest(method, requestURL, headers, nil)
+		if err != nil {
+			return nil, err
+		}
+		if r != nil {
+			prepared.stream = r
+			prepared.contentLength = contentLength
+			prepared.bodyHash = bodyHash
+		}
+
+		resp, err := c.send(ctx, prepared)
+		if err == nil && c.errorOnHTTPError {
+			if err := CheckStatus(resp); err != nil {
+				return nil, err
+			}
+		}
+		return resp, err
+	})
+}
@@ -0,0 +1,122 @@
+package pathwell_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/pathwell/connect-go/pathwell"
+)
+
+// CallStream hands the body over as it arrives: the first chunk is readable
+// while the server is still holding back the rest
+func TestCallStreamReadsIncrementally(t *testing.T) {
+	const chunkSize, chunks = 64 << 10, 64
+	chunk := bytes.Repeat([]byte("z"), chunkSize)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-ID", "req-7")
+		w.Write(chunk)
+		w.(http.Flusher).Flush()
+		<-release
+		for i := 1; i < chunks; i++ {
+			w.Write(chunk)
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := newStubClient(t, server.URL, pathwell.ClientOptions{MaxResponseBytes: chunkSize})
+	stream, err := client.CallStream(context.Background(), "GET", "https://api.example.com/download", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Body.Close()
+	if stream.StatusCode != http.StatusOK || stream.RequestID != "req-7" {
+		t.Fatalf("status %d, request ID %q", stream.StatusCode, stream.RequestID)
+	}
+
+	first := make([]byte, chunkSize)
+	if _, err := io.ReadFull(stream.Body, first); err != nil {
+		t.Fatalf("reading the first chunk while the rest is withheld: %v", err)
+	}
+	release <- struct{}{}
+
+	total := int64(len(first))
+	buf := make([]byte, 4096)
+	for {
+		n, err := stream.Body.Read(buf)
+		total += int64(n)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if total != chunkSize*chunks {
+		t.Fatalf("read %d bytes, want %d", total, chunkSize*chunks)
+	}
+}
+
+// CallStream makes a single attempt and returns non-2xx responses as they are
+func TestCallStreamBypassesRetriesAndErrors(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+		io.WriteString(w, "try later")
+	}))
+	defer server.Close()
+
+	client := newStubClient(t, server.URL, pathwell.ClientOptions{MaxRetries: 3, ErrorOnHTTPError: true})
+	stream, err := client.CallStream(context.Background(), "GET", "https://api.example.com/download", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(stream.Body)
+	stream.Body.Close()
+	if stream.StatusCode != http.StatusServiceUnavailable || string(body) != "try later" || calls != 1 {
+		t.Fatalf("status %d, body %q after %d calls", stream.StatusCode, body, calls)
+	}
+}
+
+func TestCallWithHashedBodyRejectsMismatchedLength(t *testing.T) {
+	var calls atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer server.Close()
+	client := newStubClient(t, server.URL, pathwell.ClientOptions{})
+
+	sum := sha256.Sum256([]byte("data"))
+	dataHash := hex.EncodeToString(sum[:])
+	tests := []struct {
+		name   string
+		length int64
+		hash   string
+	}{
+		{"empty length, body hash", 0, dataHash},
+		{"body length, no hash", 4, ""},
+		{"length below -1", -2, dataHash},
+		{"hash not hex", 4, "zz"},
+		{"hash too short", 4, dataHash[:32]},
+	}
+	for _, tt := range tests {
+		_, err := client.CallWithHashedBody(context.Background(), "PUT", "https://api.example.com/blob", nil,
+			strings.NewReader("data"), tt.length, tt.hash)
+		if err == nil {
+			t.Errorf("%s: accepted", tt.name)
+		}
+	}
+	if n := calls.Load(); n != 0 {
+		t.Fatalf("%d requests sent for invalid arguments", n)
+	}
+}
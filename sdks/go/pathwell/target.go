@@ -0,0 +1,57 @@
+package pathwell
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// CallTarget makes an authenticated request to the given upstream through
+// the proxy, so one Client, with one signing identity and one connection
+// pool, can serve several upstreams. target is a base URL such as
+// "https://billing.example.com/v2/" and path is resolved against it the way
+// Call resolves relative URLs against BaseURL; the client's own BaseURL is
+// not used. The origin of the result is sent as X-Pathwell-Target.
+func (c *Client) CallTarget(
+	target string,
+	method string,
+	path string,
+	headers map[string]string,
+	body interface{},
+) (*http.Response, error) {
+	return c.CallTargetContext(context.Background(), target, method, path, headers, body)
+}
+
+// CallTargetContext is CallTarget bound to the given context
+func (c *Client) CallTargetContext(
+	ctx context.Context,
+	target string,
+	method string,
+	path string,
+	headers map[string]string,
+	body interface{},
+) (*http.Response, error) {
+	requestURL, err := resolveTargetURL(target, path)
+	if err != nil {
+		return nil, err
+	}
+	return c.CallContext(ctx, method, requestURL, headers, body)
+}
+
+// resolveTargetURL resolves path against target, refusing absolute paths
+// that would silently route the call somewhere else
+func resolveTargetURL(target, path string) (string, error) {
+	baseURL, err := parseBaseURL(target)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidURL, err)
+	}
+	ref, err := url.Parse(path)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidURL, err)
+	}
+	if ref.IsAbs() || ref.Host != "" {
+		return "", fmt.Errorf("%w: path %q must be relative to the target", ErrInvalidURL, path)
+	}
+	return baseURL.ResolveReference(ref).String(), nil
+}
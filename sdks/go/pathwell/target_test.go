@@ -0,0 +1,152 @@
+package pathwell_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/pathwell/connect-go/pathwell"
+	"github.com/pathwell/connect-go/pathwell/pathwelltest"
+)
+
+// captureServer records the last request it received
+func captureServer(t *testing.T) (*httptest.Server, func() *http.Request) {
+	t.Helper()
+	var mu sync.Mutex
+	var last *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		last = r.Clone(context.Background())
+	}))
+	t.Cleanup(server.Close)
+	return server, func() *http.Request {
+		mu.Lock()
+		defer mu.Unlock()
+		return last
+	}
+}
+
+func TestRequestURLResolution(t *testing.T) {
+	tests := []struct {
+		name       string
+		options    pathwell.ClientOptions
+		url        string
+		wantPath   string
+		wantTarget string
+	}{
+		{"absolute", pathwell.ClientOptions{}, "https://api.example.com/v1/chat?q=1", "/v1/chat?q=1", "https://api.example.com"},
+		{"absolute overrides base", pathwell.ClientOptions{BaseURL: "https://other.example.com/v2/"}, "https://api.example.com/v1/chat", "/v1/chat", "https://api.example.com"},
+		{"relative to base", pathwell.ClientOptions{BaseURL: "https://api.example.com/v1/"}, "chat", "/v1/chat", "https://api.example.com"},
+		{"base without slash", pathwell.ClientOptions{BaseURL: "https://api.example.com/v1"}, "chat", "/v1/chat", "https://api.example.com"},
+		{"rooted relative to base", pathwell.ClientOptions{BaseURL: "https://api.example.com/v1/"}, "/health", "/health", "https://api.example.com"},
+		{"relative to target", pathwell.ClientOptions{TargetURL: "https://api.example.com"}, "/v1/chat", "/v1/chat", "https://api.example.com"},
+		{"base wins over target", pathwell.ClientOptions{BaseURL: "https://api.example.com/v1/", TargetURL: "https://old.example.com"}, "chat", "/v1/chat", "https://api.example.com"},
+		{"relative without base", pathwell.ClientOptions{}, "/v1/chat", "/v1/chat", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, last := captureServer(t)
+			client := newStubClient(t, server.URL, tt.options)
+			resp, err := client.Get(tt.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			r := last()
+			if got := r.URL.RequestURI(); got != tt.wantPath {
+				t.Errorf("path = %q, want %q", got, tt.wantPath)
+			}
+			if got := r.Header.Get(pathwell.DefaultTargetHeader); got != tt.wantTarget {
+				t.Errorf("target = %q, want %q", got, tt.wantTarget)
+			}
+		})
+	}
+}
+
+func TestCallTarget(t *testing.T) {
+	server, last := captureServer(t)
+	client := newStubClient(t, server.URL, pathwell.ClientOptions{BaseURL: "https://api.example.com/v1/"})
+
+	resp, err := client.CallTarget("https://billing.example.com/v2/", "GET", "invoices", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	r := last()
+	if r.URL.Path != "/v2/invoices" || r.Header.Get(pathwell.DefaultTargetHeader) != "https://billing.example.com" {
+		t.Fatalf("sent %s with target %q", r.URL.Path, r.Header.Get(pathwell.DefaultTargetHeader))
+	}
+
+	for _, path := range []string{"https://evil.example.com/invoices", "//evil.example.com/invoices"} {
+		if _, err := client.CallTarget("https://billing.example.com/v2/", "GET", path, nil, nil); !errors.Is(err, pathwell.ErrInvalidURL) {
+			t.Errorf("CallTarget(%q) error = %v, want ErrInvalidURL", path, err)
+		}
+	}
+}
+
+// One client routes to several upstreams, each call carrying its own
+// target, and the verifying proxy accepts every one of them
+func TestCallTargetSelection(t *testing.T) {
+	proxy, client := newProxyClient(t, pathwelltest.ProxyOptions{}, pathwell.ClientOptions{
+		TargetURL:              "https://default.example.com",
+		IncludeHostInSignature: true,
+	})
+
+	calls := []struct {
+		target, path string
+		wantTarget   string
+		wantPath     string
+	}{
+		{"https://billing.example.com/v2/", "invoices?status=open", "https://billing.example.com", "/v2/invoices?status=open"},
+		{"https://search.example.com", "/query", "https://search.example.com", "/query"},
+		{"http://internal.example.com:8443/api", "items/1", "http://internal.example.com:8443", "/api/items/1"},
+		{"https://billing.example.com/v2/", "/health", "https://billing.example.com", "/health"},
+	}
+	for _, call := range calls {
+		resp, err := client.CallTarget(call.target, "GET", call.path, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("%s %s: status %d", call.target, call.path, resp.StatusCode)
+		}
+	}
+
+	requests := proxy.Requests()
+	for i, call := range calls {
+		r := requests[i]
+		if r.Target != call.wantTarget || r.Path != call.wantPath {
+			t.Errorf("call %d: target %q path %q, want %q %q", i, r.Target, r.Path, call.wantTarget, call.wantPath)
+		}
+		if host := r.Header.Get(pathwell.DefaultSignedHostHeader); host != call.wantTarget {
+			t.Errorf("call %d: signed host %q, want %q", i, host, call.wantTarget)
+		}
+	}
+
+	// The client's own target is untouched by CallTarget
+	resp, err := client.Get("/x", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if got := proxy.Requests()[len(calls)].Target; got != "https://default.example.com" {
+		t.Errorf("target after CallTarget = %q", got)
+	}
+}
+
+func TestCallTargetRejectsInvalidTarget(t *testing.T) {
+	server, _ := captureServer(t)
+	client := newStubClient(t, server.URL, pathwell.ClientOptions{})
+	for _, target := range []string{"", "billing.example.com", "/v2/", "://bad"} {
+		if _, err := client.CallTarget(target, "GET", "invoices", nil, nil); !errors.Is(err, pathwell.ErrInvalidURL) {
+			t.Errorf("CallTarget(%q) error = %v, want ErrInvalidURL", target, err)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package pathwell
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// Tracer creates spans around client calls. It is implemented by adapting a
+// tracing library such as OpenTelemetry, so the SDK itself carries no
+// tracing dependency.
+type Tracer interface {
+	// Start starts a span as a child of any span in ctx
+	Start(ctx context.Context, name string) (context.Context, Span)
+	// Inject writes the trace context in ctx into outgoing request headers
+	Inject(ctx context.Context, header http.Header)
+}
+
+// Span is a single traced operation started by a Tracer
+type Span interface {
+	SetAttribute(key string, value interface{})
+	RecordError(err error)
+	End()
+}
+
+// recordSpan sets the outcome of a call on its span
+func recordSpan(span Span, method string, resp *http.Response, err error, elapsed time.Duration) {
+	span.SetAttribute("http.method", method)
+	span.SetAttribute("pathwell.duration_ms", elapsed.Milliseconds())
+
+	var apiErr *APIError
+	switch {
+	case resp != nil:
+		span.SetAttribute("http.status_code", resp.StatusCode)
+	case errors.As(err, &apiErr):
+		span.SetAttribute("http.status_code", apiErr.StatusCode)
+	}
+
+	if err != nil {
+		span.RecordError(err)
+	}
+}
@@ -0,0 +1,78 @@
+package pathwell
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Connection pool defaults for the transport the SDK builds itself. Every
+// request goes to the same proxy host, so the per-host limit matches the
+// total rather than the stdlib default of 2.
+const (
+	defaultMaxIdleConns        = 100
+	defaultMaxIdleConnsPerHost = 100
+	defaultIdleConnTimeout     = 90 * time.Second
+)
+
+// newTransport clones http.DefaultTransport with the pool, protocol, dialing,
+// TLS and egress proxy settings in options, falling back to the defaults
+// above for unset fields
+func newTransport(options ClientOptions) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ForceAttemptHTTP2 = true
+	if options.DisableHTTP2 {
+		// A non-nil, empty TLSNextProto is how net/http turns HTTP/2 off
+		transport.ForceAttemptHTTP2 = false
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+	transport.MaxIdleConns = defaultMaxIdleConns
+	transport.MaxIdleConnsPerHost = defaultMaxIdleConnsPerHost
+	transport.IdleConnTimeout = defaultIdleConnTimeout
+
+	if options.MaxIdleConns > 0 {
+		transport.MaxIdleConns = options.MaxIdleConns
+	}
+	if options.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = options.MaxIdleConnsPerHost
+	}
+	if options.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = options.IdleConnTimeout
+	}
+	if options.DialContext != nil {
+		transport.DialContext = options.DialContext
+	}
+	if options.EgressProxyURL != "" {
+		// validateEgressProxyURL has already checked that the URL parses
+		egress, _ := url.Parse(options.EgressProxyURL)
+		transport.Proxy = http.ProxyURL(egress)
+	}
+	if options.TLSRootCAs != nil || len(options.TLSClientCertificates) > 0 || options.TLSMinVersion != 0 {
+		transport.TLSClientConfig = &tls.Config{
+			RootCAs:      options.TLSRootCAs,
+			Certificates: options.TLSClientCertificates,
+			MinVersion:   options.TLSMinVersion,
+		}
+	}
+	return transport
+}
+
+// validateEgressProxyURL rejects egress proxy URLs without a supported
+// scheme and a host
+func validateEgressProxyURL(egressURL string) error {
+	parsed, err := url.Parse(egressURL)
+	if err != nil {
+		return fmt.Errorf("invalid egress proxy URL: %w", err)
+	}
+	switch parsed.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return fmt.Errorf("invalid egress proxy URL %q: scheme must be http, https or socks5", parsed.Redacted())
+	}
+	if parsed.Host == "" {
+		return fmt.Errorf("invalid egress proxy URL %q: missing host", parsed.Redacted())
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package pathwell
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrKeyExpired is returned, wrapped, when signing after KeyNotAfter
+var ErrKeyExpired = errors.New("signing key has expired")
+
+// ErrKeyNotYetValid is returned, wrapped, when signing before KeyNotBefore
+var ErrKeyNotYetValid = errors.New("signing key is not yet valid")
+
+// signingKey is the key a Client signs with, its ID and validity window
+type signingKey struct {
+	signer    crypto.Signer
+	keyID     string
+	notBefore time.Time
+	notAfter  time.Time
+}
+
+// checkValidity rejects signing at t outside the key's validity window.
+// Zero bounds are not checked.
+func (k signingKey) checkValidity(t time.Time) error {
+	if !k.notBefore.IsZero() && t.Before(k.notBefore) {
+		return fmt.Errorf("%w: valid from %s", ErrKeyNotYetValid, k.notBefore.Format(time.RFC3339))
+	}
+	if !k.notAfter.IsZero() && t.After(k.notAfter) {
+		return fmt.Errorf("%w: expired at %s", ErrKeyExpired, k.notAfter.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// validateKeyID rejects key IDs that would break the signed payload lines
+func validateKeyID(keyID string) error {
+	if strings.ContainsAny(keyID, "\r\n") {
+		return fmt.Errorf("invalid key ID %q: must not contain line breaks", keyID)
+	}
+	return nil
+}
@@ -0,0 +1,7 @@
+package pathwell
+
+// Version is the version of this SDK
+const Version = "0.1.0"
+
+// defaultUserAgent identifies SDK traffic in proxy logs
+const defaultUserAgent = "pathwell-go/" + Version